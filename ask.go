@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"time"
+)
+
+// Ask is a request to enable or disable an option for a side.
+type Ask struct {
+	Opt    Option
+	Side   Side
+	Enable bool
+}
+
+// Result is the outcome of an Ask.
+type Result struct {
+	Ask
+	State NegState // Negotiation state when Negotiate returned
+	Err   error    // Error from asking, if any
+}
+
+// OK indicates if the option reached the requested state.
+func (r Result) OK() bool {
+	want := NegNo
+	if r.Enable {
+		want = NegYes
+	}
+
+	return r.Err == nil && r.State == want
+}
+
+// settled indicates if the negotiation is no longer in progress.
+func (r Result) settled() bool {
+	return r.Err != nil || r.State == NegYes || r.State == NegNo
+}
+
+// Negotiate asks for each option and blocks until every negotiation is
+// settled or the context is done.  Received data is retained for the
+// next Read.
+//
+// If the underlying ReadWriter supports read deadlines then a blocked
+// read is interrupted when the context is done by setting the read
+// deadline, which is cleared before returning.  In that case any read
+// deadline set by the caller is not preserved.  An error is returned if
+// reading failed or the context was done before every negotiation was
+// settled.
+func (t *Ctx) Negotiate(ctx context.Context, asks ...Ask) ([]Result, error) {
+	results := make([]Result, len(asks))
+	for i, a := range asks {
+		results[i].Ask = a
+		if a.Side == Us {
+			results[i].Err = t.AskUs(a.Opt, a.Enable)
+		} else {
+			results[i].Err = t.AskHim(a.Opt, a.Enable)
+		}
+	}
+
+	if d, ok := t.rw.(interface{ SetReadDeadline(time.Time) error }); ok {
+		done := make(chan struct{})
+		stop := context.AfterFunc(ctx, func() {
+			d.SetReadDeadline(time.Now())
+			close(done)
+		})
+		defer func() {
+			if !stop() {
+				// The deadline was set to interrupt a read so clear it.
+				<-done
+				d.SetReadDeadline(time.Time{})
+			}
+		}()
+	}
+
+	var err error
+	for !t.settled(results) {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if _, err = t.Read(nil); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			break
+		}
+	}
+
+	return results, err
+}
+
+// settled updates the state of each result and indicates if they are
+// all settled.
+func (t *Ctx) settled(results []Result) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	settled := true
+	for i := range results {
+		s := t.os.load(results[i].Opt.Byte())
+		results[i].State = OptionState{Him: s.him, Us: s.us}.Side(results[i].Side)
+		settled = settled && results[i].settled()
+	}
+
+	return settled
+}
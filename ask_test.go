@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestResultOK(t *testing.T) {
+	tests := []struct {
+		enable bool
+		state  NegState
+		want   bool
+	}{
+		{true, NegYes, true},
+		{true, NegNo, false},
+		{true, NegWantYes, false},
+		{true, NegWantNoOpp, false},
+		{false, NegNo, true},
+		{false, NegYes, false},
+		{false, NegWantNo, false},
+		{false, NegWantYesOpp, false},
+	}
+
+	for _, test := range tests {
+		r := Result{Ask: Ask{Enable: test.enable}, State: test.state}
+		if ok := r.OK(); ok != test.want {
+			t.Errorf("enable %v state %s: OK %v, want %v", test.enable, test.state, ok, test.want)
+		}
+	}
+}
+
+func TestNegotiateDeadline(t *testing.T) {
+	opt := AcceptOpt{Code: 24, Name: "Terminal-Type"}
+
+	// A deadline set by the caller is preserved when the context is not
+	// done.
+	server, client := net.Pipe()
+	defer client.Close()
+	time.AfterFunc(time.Second, func() { client.Close() })
+	tn := NewReadWriter(server, opt)
+	go io.Copy(io.Discard, client)
+	go client.Write([]byte("\xff\xfb\x18"))
+
+	tn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := tn.Negotiate(context.Background(), Ask{Opt: opt, Side: Him, Enable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tn.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Read error %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	// The deadline used to interrupt a read when the context is done is
+	// cleared.
+	tn.SetReadDeadline(time.Time{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tn.Negotiate(ctx, Ask{Opt: opt, Side: Us, Enable: true}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Negotiate error %v, want %v", err, context.DeadlineExceeded)
+	}
+	go client.Write([]byte("a"))
+	if _, err := tn.Read(make([]byte, 1)); err != nil {
+		t.Errorf("Read error %v after Negotiate", err)
+	}
+}
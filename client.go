@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"net"
+)
+
+// himOpt is an option that only he is allowed to enable.
+type himOpt struct {
+	AcceptOpt
+}
+
+func (himOpt) LetUs() bool { return false }
+
+// usOpt is an option that only we are willing to enable.
+type usOpt struct {
+	AcceptOpt
+}
+
+func (usOpt) LetHim() bool { return false }
+
+// DefaultTermType is the terminal type reported when dialing, unless a
+// Terminal-Type option is provided.
+var DefaultTermType = "UNKNOWN"
+
+// RFC1091 Terminal-Type subnegotiation commands.
+const (
+	termIs   byte = 0
+	termSend byte = 1
+)
+
+// termOpt is a Terminal-Type option that reports DefaultTermType.
+type termOpt struct {
+	usOpt
+}
+
+func (o termOpt) Params(tn *Ctx, params []byte) {
+	if len(params) > 0 && params[0] == termSend {
+		tn.SendParams(o, append([]byte{termIs}, DefaultTermType...))
+	}
+}
+
+// nawsOpt is a Negotiate About Window Size option that reports an 80x24
+// window.
+type nawsOpt struct {
+	usOpt
+}
+
+func (o nawsOpt) SetUs(tn *Ctx, enabled bool) {
+	if enabled {
+		tn.SendParams(o, []byte{0, 80, 0, 24})
+	}
+}
+
+// clientOpts are the options available for negotiation by default when
+// dialing.
+var clientOpts = []Option{
+	himOpt{AcceptOpt{Code: 1, Name: "Echo"}},
+	AcceptOpt{Code: optSGA, Name: "Suppress Go Ahead"},
+	termOpt{usOpt{AcceptOpt{Code: 24, Name: "Terminal-Type"}}},
+	nawsOpt{usOpt{AcceptOpt{Code: 31, Name: "Negotiate About Window Size"}}},
+}
+
+// Dial connects to the address on the named network and returns a telnet
+// context for the connection.
+//
+// He is allowed to enable Echo and Suppress Go Ahead and we are willing
+// to enable Suppress Go Ahead.  Any options that are provided are also
+// available for negotiation and replace a default with the same byte
+// code.
+//
+// We are also willing to enable Terminal-Type, reporting DefaultTermType,
+// and Negotiate About Window Size, reporting an 80x24 window.  Provide
+// option.Term and option.NAWS to report the actual terminal.
+func Dial(network, addr string, opts ...Option) (*Ctx, error) {
+	return DialContext(context.Background(), network, addr, opts...)
+}
+
+// DialContext is like Dial but connects using the provided context.
+func DialContext(ctx context.Context, network, addr string, opts ...Option) (*Ctx, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReadWriter(conn, append(clientOpts, opts...)...), nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"io"
+	"testing"
+)
+
+func TestClientOpts(t *testing.T) {
+	sb := func(b ...byte) string {
+		return string(append(append([]byte{byte(iac), byte(sb)}, b...), byte(iac), byte(se)))
+	}
+	cmd := func(cmd Command, code byte) string {
+		return string([]byte{byte(iac), byte(cmd), code})
+	}
+
+	defer func(s string) { DefaultTermType = s }(DefaultTermType)
+
+	tests := []struct {
+		name     string
+		termType string
+		in       string
+		want     string
+	}{
+		{"echo", "", cmd(will, 1) + cmd(do, 1), cmd(do, 1) + cmd(wont, 1)},
+		{"ttype", "UNKNOWN", cmd(do, 24) + sb(24, termSend),
+			cmd(will, 24) + sb(24, termIs, 'U', 'N', 'K', 'N', 'O', 'W', 'N')},
+		{"ttype custom", "XTERM", cmd(do, 24) + sb(24, termSend),
+			cmd(will, 24) + sb(24, termIs, 'X', 'T', 'E', 'R', 'M')},
+		{"ttype him", "", cmd(will, 24), cmd(dont, 24)},
+		{"naws", "", cmd(do, 31), cmd(will, 31) + sb(31, 0, 80, 0, 24)},
+		{"naws him", "", cmd(will, 31), cmd(dont, 31)},
+	}
+
+	for _, test := range tests {
+		DefaultTermType = test.termType
+		rw := newRWBuf(test.in)
+		tn := NewReadWriter(rw, clientOpts...)
+		if _, err := io.ReadAll(tn); err != nil {
+			t.Fatal(err)
+		}
+		if rw.out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, rw.out.String(), test.want)
+		}
+	}
+}
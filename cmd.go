@@ -0,0 +1,128 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import "log/slog"
+
+// CmdHandler is called when a command is received in the data stream.
+type CmdHandler func(tn *Ctx, cmd Command)
+
+// HandleCmd registers a handler for a command received in the data
+// stream, replacing any default behavior.  A nil handler restores the
+// default.  A Synch is always completed when its DM is received, before
+// any DM handler is called.
+//
+// Only the RFC854 control commands (EOF, SP, AP, EOR, NOP, DM, BRK, IP,
+// AO, AYT, EC, EL, and GA) are dispatched to handlers.
+func (t *Ctx) HandleCmd(cmd Command, h CmdHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if h == nil {
+		delete(t.cmds, cmd)
+		return
+	}
+	t.cmds[cmd] = h
+}
+
+// command executes a control command received in the data stream.
+func (t *Ctx) command(cmd Command) {
+	t.emit(Event{Kind: EventCmd, Cmd: cmd})
+
+	if cmd == DM {
+		// A Synch always completes, even with a handler, or data would be
+		// discarded indefinitely.
+		t.synch()
+	}
+
+	if h, found := t.cmds[cmd]; found {
+		slog.Debug("handling command", "cmd", cmd)
+
+		t.mu.Unlock()
+		h(t, cmd)
+		t.mu.Lock()
+		return
+	}
+
+	switch cmd {
+	case AYT:
+		if f := t.ayt; f != nil {
+			t.mu.Unlock()
+			resp := f()
+			t.mu.Lock()
+
+			t.write(resp)
+			t.goAhead()
+		}
+	case DM, NOP:
+		// No operation
+	default:
+		slog.Debug("ignoring unhandled command", "cmd", cmd)
+	}
+}
+
+// defaultAYT produces the default Are You There response.
+func defaultAYT() []byte {
+	return []byte("I am here\r\n")
+}
+
+// SetAYT sets the function that produces the response to an Are You There
+// command.  The response is escaped and followed by a Go Ahead unless
+// Suppress Go Ahead is enabled for us.  A nil function disables the
+// response.
+func (t *Ctx) SetAYT(f func() []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ayt = f
+}
+
+// goAhead sends a Go Ahead unless Suppress Go Ahead is enabled for us.
+func (t *Ctx) goAhead() {
+	if t.os.load(optSGA).us != NegYes {
+		t.emit(Event{Kind: EventCmdSent, Cmd: GA})
+		t.rw.Write([]byte{byte(iac), byte(GA)})
+	}
+}
+
+// AbortOutput is a CmdHandler for AO that sends a Synch so the client
+// discards any output that is still in flight.
+func AbortOutput(tn *Ctx, cmd Command) {
+	tn.SendSynch()
+}
+
+// EraseChar is a CmdHandler for EC that erases the last character of
+// data that has not been returned by Read yet.
+//
+// Only data received in the same read as the command can be erased.  In
+// character mode the data preceding it has usually been returned already
+// so the application must do its own editing.
+func EraseChar(tn *Ctx, cmd Command) {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+
+	if n := len(tn.pending); n > 0 && !isEOL(tn.pending[n-1]) {
+		tn.pending = tn.pending[:n-1]
+	}
+}
+
+// EraseLine is a CmdHandler for EL that erases the current line of data
+// that has not been returned by Read yet.  Like EraseChar it only applies
+// to data received in the same read as the command.
+func EraseLine(tn *Ctx, cmd Command) {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+
+	n := len(tn.pending)
+	for n > 0 && !isEOL(tn.pending[n-1]) {
+		n--
+	}
+	tn.pending = tn.pending[:n]
+}
+
+// isEOL indicates if a byte terminates a line.
+func isEOL(b byte) bool {
+	return b == '\r' || b == '\n' || b == 0
+}
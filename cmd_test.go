@@ -0,0 +1,115 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+// iacCmd returns the encoding of a command.
+func iacCmd(cmd Command) string {
+	return string([]byte{byte(iac), byte(cmd)})
+}
+
+func TestHandleCmd(t *testing.T) {
+	tn := NewReadWriter(newRWBuf("a" + iacCmd(IP) + "b" + iacCmd(BRK) + iacCmd(IP) + "c"))
+	var cmds []Command
+	h := func(_ *Ctx, cmd Command) { cmds = append(cmds, cmd) }
+	tn.HandleCmd(IP, h)
+	tn.HandleCmd(BRK, h)
+	tn.HandleCmd(BRK, nil)
+
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "abc" {
+		t.Errorf("read %q, want %q", b, "abc")
+	}
+	if want := []Command{IP, IP}; !slices.Equal(cmds, want) {
+		t.Errorf("handled %v, want %v", cmds, want)
+	}
+}
+
+func TestEraseCmds(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ab" + iacCmd(EC) + "c", "ac"},
+		{"a\n" + iacCmd(EC) + "b", "a\nb"},
+		{iacCmd(EC) + "a", "a"},
+		{"one\ntwo" + iacCmd(EL) + "three", "one\nthree"},
+		{"one\r\n" + iacCmd(EL) + "two", "one\r\ntwo"},
+		{"ab" + iacCmd(EC) + iacCmd(EC) + iacCmd(EC) + "c" + iacCmd(EL) + "d", "d"},
+	}
+
+	for _, test := range tests {
+		tn := NewReadWriter(newRWBuf(test.in))
+		tn.HandleCmd(EC, EraseChar)
+		tn.HandleCmd(EL, EraseLine)
+
+		b, err := io.ReadAll(tn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("%q: read %q, want %q", test.in, b, test.want)
+		}
+	}
+}
+
+func TestAbortOutput(t *testing.T) {
+	rw := newRWBuf("a" + iacCmd(AO) + "b")
+	tn := NewReadWriter(rw)
+	tn.HandleCmd(AO, AbortOutput)
+
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "ab" {
+		t.Errorf("read %q, want %q", b, "ab")
+	}
+	if want := iacCmd(DM); rw.out.String() != want {
+		t.Errorf("wrote %q, want %q", rw.out.String(), want)
+	}
+}
+
+func TestAYT(t *testing.T) {
+	sga := AcceptOpt{Code: optSGA, Name: "Suppress Go Ahead"}
+	doSGA := string([]byte{byte(iac), byte(do), optSGA})
+	willSGA := string([]byte{byte(iac), byte(will), optSGA})
+
+	tests := []struct {
+		name string
+		in   string
+		ayt  func() []byte
+		set  bool
+		want string
+	}{
+		{"default", iacCmd(AYT), nil, false, "I am here\r\n" + iacCmd(GA)},
+		{"custom", iacCmd(AYT), func() []byte { return []byte("yes\xff") }, true, "yes\xff\xff" + iacCmd(GA)},
+		{"disabled", iacCmd(AYT), nil, true, ""},
+		{"sga", doSGA + iacCmd(AYT), nil, false, willSGA + "I am here\r\n"},
+	}
+
+	for _, test := range tests {
+		rw := newRWBuf(test.in)
+		tn := NewReadWriter(rw, sga)
+		if test.set {
+			tn.SetAYT(test.ayt)
+		}
+
+		if _, err := io.ReadAll(tn); err != nil {
+			t.Fatal(err)
+		}
+		if rw.out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, rw.out.String(), test.want)
+		}
+	}
+}
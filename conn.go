@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// ErrNotConn is returned when a deadline is set on, or Upgrade is called
+// for, a context whose underlying ReadWriter is not a net.Conn.
+var ErrNotConn = errors.New("not a net.Conn")
+
+// A context is a net.Conn when its underlying ReadWriter is one.
+var _ net.Conn = (*Ctx)(nil)
+
+// conn returns the underlying ReadWriter if it's a net.Conn.
+func (t *Ctx) conn() (net.Conn, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.rw.(net.Conn)
+	return c, ok
+}
+
+// LocalAddr returns the local network address of the underlying
+// connection or nil if it's not a net.Conn.
+func (t *Ctx) LocalAddr() net.Addr {
+	if c, ok := t.conn(); ok {
+		return c.LocalAddr()
+	}
+
+	return nil
+}
+
+// RemoteAddr returns the remote network address of the underlying
+// connection or nil if it's not a net.Conn.
+func (t *Ctx) RemoteAddr() net.Addr {
+	if c, ok := t.conn(); ok {
+		return c.RemoteAddr()
+	}
+
+	return nil
+}
+
+// SetDeadline sets the read and write deadlines of the underlying
+// connection.
+func (t *Ctx) SetDeadline(tm time.Time) error {
+	if c, ok := t.conn(); ok {
+		return c.SetDeadline(tm)
+	}
+
+	return ErrNotConn
+}
+
+// SetReadDeadline sets the read deadline of the underlying connection.
+func (t *Ctx) SetReadDeadline(tm time.Time) error {
+	if c, ok := t.conn(); ok {
+		return c.SetReadDeadline(tm)
+	}
+
+	return ErrNotConn
+}
+
+// SetWriteDeadline sets the write deadline of the underlying connection.
+func (t *Ctx) SetWriteDeadline(tm time.Time) error {
+	if c, ok := t.conn(); ok {
+		return c.SetWriteDeadline(tm)
+	}
+
+	return ErrNotConn
+}
+
+// Close stops any negotiation timers and closes the underlying
+// ReadWriter if it's an io.Closer.
+func (t *Ctx) Close() error {
+	t.mu.Lock()
+	for k, nt := range t.timers {
+		nt.timer.Stop()
+		delete(t.timers, k)
+	}
+	rw := t.rw
+	t.mu.Unlock()
+
+	if c, ok := rw.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"io"
+	"context"
 	"log/slog"
 	"net"
 	"os"
@@ -9,81 +9,43 @@ import (
 
 	"github.com/ebarkie/telnet"
 	"github.com/ebarkie/telnet/option"
+	"github.com/ebarkie/telnet/readline"
 )
 
-// negotiate creates a telnet ReadWriter, asks for character mode options, and blocks until
-// the options are confirmed or the wait duration passes.
-func negotiate(conn net.Conn, wait time.Duration, opts ...telnet.Option) (*telnet.Ctx, bool) {
-	// Create telnet ReadWriter with options.
-	echo := &option.Echo{}
-	sga := &option.SGA{}
-	tn := telnet.NewReadWriter(conn, append(opts, echo, sga)...)
-
-	// Send option negotiations.
-	tn.AskUs(sga, true)
-	tn.AskUs(echo, true)
-
-	// Wait for echo confirmation.
-	conn.SetReadDeadline(time.Now().Add(wait))
-	defer conn.SetReadDeadline(time.Time{})
-
-	var err error
-	for err == nil {
-		_, err = tn.Read([]byte{})
-		if echo.Us {
-			break
-		}
-	}
-
-	return tn, echo.Us
-}
-
 func serve(conn net.Conn) {
 	defer conn.Close()
 	defer slog.Info("connection closed", "addr", conn.RemoteAddr())
 
-	// Create telnet ReadWriter with character mode enabled.
+	// Create telnet ReadWriter with options.
+	echo := &option.Echo{}
+	sga := &option.SGA{}
 	term := &option.Term{}
-	tn, ok := negotiate(conn, 2*time.Second, term)
-	if !ok {
+	tn := telnet.NewReadWriter(conn, echo, sga, term)
+
+	// Negotiate character mode and wait for confirmation.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res, _ := tn.Negotiate(ctx,
+		telnet.Ask{Opt: sga, Side: telnet.Us, Enable: true},
+		telnet.Ask{Opt: echo, Side: telnet.Us, Enable: true})
+	if !res[1].OK() {
 		tn.Write([]byte("Protocol negotiation failed.\r\n"))
 		return
 	}
 	tn.AskHim(term, true)
+	tn.SetNVT(true)
 
-	// Welcome banner and prompt.
-	tn.Write([]byte("Welcome to a test telnet server!\r\n"))
-	tn.Write([]byte("\r\n> "))
+	// Welcome banner.
+	tn.Write([]byte("Welcome to a test telnet server!\n\n"))
 
-	// Process input until connection is closed.
-	buf := make([]byte, 1024)
-	var nt int // Number typed
+	// Process lines until connection is closed.
+	ed := readline.New(tn)
 	for {
-		n, err := tn.Read(buf)
-		if err == io.EOF {
+		line, err := ed.ReadLine("> ")
+		if err != nil {
 			return
 		}
-		slog.Info("read", "data", buf[:n], "n", n)
-
-		for i := range n {
-			switch buf[i] {
-			case 0x00, 0x0a:
-				// A null or newline indicates end of line
-				tn.Write([]byte("\r\n> "))
-				nt = 0
-			case 0x0d:
-				// Ignore carriage returns
-			case 0x08, 0x7f:
-				// Backspace (^H or ^?)
-				if nt > 0 {
-					tn.Write([]byte{0x08, 0x20, 0x08})
-					nt--
-				}
-			default:
-				tn.Write([]byte{buf[i]})
-				nt++
-			}
-		}
+		slog.Info("read", "line", line)
 	}
 }
 
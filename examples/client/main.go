@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/ebarkie/telnet"
+	"github.com/ebarkie/telnet/option"
+)
+
+func main() {
+	// Connect to the test telnet server answering for the terminal type.
+	term := &option.Term{Us: []string{"XTERM"}}
+	tn, err := telnet.Dial("tcp", "127.0.0.1:8023", term)
+	if err != nil {
+		panic(err)
+	}
+	defer tn.Close()
+	slog.Info("connected")
+
+	// Copy standard input to the server and the server to standard output
+	// until the connection is closed.
+	go io.Copy(tn, os.Stdin)
+	io.Copy(os.Stdout, tn)
+}
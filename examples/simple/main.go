@@ -1,29 +1,25 @@
 package main
 
 import (
-	"io"
 	"log/slog"
 	"net"
 
 	"github.com/ebarkie/telnet"
 )
 
-func serve(conn net.Conn) {
-	defer conn.Close()
-	defer slog.Info("connection closed", "addr", conn.RemoteAddr())
-
-	// Create telnet ReadWriter with no options.
-	tn := telnet.NewReadWriter(conn)
+func serve(s *telnet.Session) {
+	slog.Info("accepted connection", "addr", s.RemoteAddr())
+	defer slog.Info("connection closed", "addr", s.RemoteAddr())
 
 	// Welcome banner.
-	tn.Write([]byte("Welcome to a test telnet server!\r\n\r\n"))
+	s.Write([]byte("Welcome to a test telnet server!\r\n\r\n"))
 
 	// Process input until connection is closed.
 	buf := make([]byte, 1024)
 	for {
-		tn.Write([]byte("> "))
-		n, err := tn.Read(buf)
-		if err == io.EOF {
+		s.Write([]byte("> "))
+		n, err := s.Read(buf)
+		if err != nil {
 			return
 		}
 		slog.Info("read", "data", buf[:n], "n", n)
@@ -31,21 +27,14 @@ func serve(conn net.Conn) {
 }
 
 func main() {
-	// Create TCP listener.
-	addr := net.JoinHostPort("127.0.0.1", "8023")
-	slog.Info("listening", "addr", addr)
-	l, err := net.Listen("tcp", addr)
-	if err != nil {
-		panic(err)
+	// Create telnet server with no options.
+	srv := &telnet.Server{
+		Addr:    net.JoinHostPort("127.0.0.1", "8023"),
+		Handler: telnet.HandlerFunc(serve),
 	}
 
-	for {
-		conn, err := l.Accept()
-		if err != nil {
-			slog.Error("accept error", "err", err)
-			continue
-		}
-		slog.Info("accepted connection", "addr", conn.RemoteAddr())
-		go serve(conn)
+	slog.Info("listening", "addr", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil {
+		panic(err)
 	}
 }
@@ -16,91 +16,144 @@ var (
 	ErrNegAskDenied = errors.New("ask violates let")
 )
 
-// negState is a RFC1143 option negotiation state.
-type negState byte
+// NegotiationError is reported when he answers a negotiation in a way
+// that violates the protocol.
+type NegotiationError struct {
+	Opt   Option
+	State NegState // State of the option before the command was received
+	Cmd   Command  // Received command
+}
+
+func (e *NegotiationError) Error() string {
+	asked := dont
+	if e.Cmd == do {
+		asked = wont
+	}
+
+	return fmt.Sprintf("%s option %s answered by %s", asked, e.Opt, e.Cmd)
+}
+
+// ParamsError is reported when an unexpected command is received within
+// subnegotiation parameters.
+type ParamsError struct {
+	Opt  Option
+	Byte byte
+}
+
+func (e *ParamsError) Error() string {
+	return fmt.Sprintf("option %s unexpected byte %d in subnegotiation", e.Opt, e.Byte)
+}
+
+// ParamsLenError is reported when subnegotiation parameters exceed the
+// maximum length.  The subnegotiation is discarded.
+type ParamsLenError struct {
+	Opt Option
+	Max int
+}
+
+func (e *ParamsLenError) Error() string {
+	return fmt.Sprintf("option %s subnegotiation exceeds %d bytes", e.Opt, e.Max)
+}
+
+//go:generate stringer -type NegState -trimprefix Neg
+
+// NegState is a RFC1143 option negotiation state.
+type NegState byte
 
 const (
-	nsNo         negState = iota // Disabled
-	nsYes                        // Enabled
-	nsWantNo                     // Negotiating for disable
-	nsWantNoOpp                  // Want to enable but previous disable negotiation not complete
-	nsWantYes                    // Negotiating for enable
-	nsWantYesOpp                 // Want to disable but previous enable negotiation not complete
+	NegNo         NegState = iota // Disabled
+	NegYes                        // Enabled
+	NegWantNo                     // Negotiating for disable
+	NegWantNoOpp                  // Want to enable but previous disable negotiation not complete
+	NegWantYes                    // Negotiating for enable
+	NegWantYesOpp                 // Want to disable but previous enable negotiation not complete
 )
 
+// active indicates if an option is enabled in a negotiation state, which
+// includes while negotiating for it to be disabled.
+func active(ns NegState) bool {
+	return ns == NegYes || ns == NegWantNo || ns == NegWantNoOpp
+}
+
 func (t *Ctx) indicate(cmd Command, code byte) {
 	s := t.os.load(code)
 	slog.Debug("indicating option", "cmd", cmd, "opt", s.opt)
+	t.emit(Event{Kind: EventSent, Cmd: cmd, Opt: s.opt})
 	t.rw.Write([]byte{byte(iac), byte(cmd), code})
 }
 
 func (t *Ctx) ask(cmd Command, opt Option) (err error) {
 	slog.Debug("asking option", "cmd", cmd, "opt", opt)
 	s := t.os.load(opt.Byte())
+	old := s
 
 	switch cmd {
 	case will:
 		// We are asking if we can enable an option.
 		switch s.us {
-		case nsNo:
+		case NegNo:
 			if s.opt.LetUs() {
 				t.indicate(will, opt.Byte())
-				s.us = nsWantYes
+				s.us = NegWantYes
 			} else {
 				err = ErrNegAskDenied
 			}
-		case nsWantNo:
-			s.us = nsWantNoOpp
-		case nsWantYesOpp:
-			s.us = nsWantYes
+		case NegWantNo:
+			s.us = NegWantNoOpp
+		case NegWantYesOpp:
+			s.us = NegWantYes
 		}
 	case wont:
 		// We are indicating that we are disabling an option.
 		switch s.us {
-		case nsYes:
+		case NegYes:
 			t.indicate(wont, opt.Byte())
-			s.us = nsWantNo
-		case nsWantNoOpp:
-			s.us = nsWantNo
-		case nsWantYes:
-			s.us = nsWantYesOpp
+			s.us = NegWantNo
+		case NegWantNoOpp:
+			s.us = NegWantNo
+		case NegWantYes:
+			s.us = NegWantYesOpp
 		}
 	case do:
 		// We are asking that he enable an option.
 		switch s.him {
-		case nsNo:
+		case NegNo:
 			if s.opt.LetHim() {
 				t.indicate(do, opt.Byte())
-				s.him = nsWantYes
+				s.him = NegWantYes
 			} else {
 				err = ErrNegAskDenied
 			}
-		case nsWantNo:
-			s.him = nsWantNoOpp
-		case nsWantYesOpp:
-			s.him = nsWantYes
+		case NegWantNo:
+			s.him = NegWantNoOpp
+		case NegWantYesOpp:
+			s.him = NegWantYes
 		}
 	case dont:
 		// We are asking that he disable an option.
 		switch s.him {
-		case nsYes:
+		case NegYes:
 			t.indicate(dont, opt.Byte())
-			s.him = nsWantNo
-		case nsWantNoOpp:
-			s.him = nsWantNo
-		case nsWantYes:
-			s.him = nsWantYesOpp
+			s.him = NegWantNo
+		case NegWantNoOpp:
+			s.him = NegWantNo
+		case NegWantYes:
+			s.him = NegWantYesOpp
 		}
 	}
 
+	t.changed(old, s)
 	t.os.store(s)
+	t.watch(opt.Byte())
 
 	return
 }
 
 func (t *Ctx) negotiate(cmd Command, code byte) (err error) {
-	s := t.os.load(code)
+	s := t.lookup(code)
+	old := s
 	slog.Debug("received option", "cmd", cmd, "opt", s.opt)
+	t.emit(Event{Kind: EventReceived, Cmd: cmd, Opt: s.opt})
 
 	var callback func(*Ctx, bool)
 	var enabled bool
@@ -110,111 +163,113 @@ func (t *Ctx) negotiate(cmd Command, code byte) (err error) {
 		// He is asking if he can enable an option or accepting our
 		// request that he enable an option.
 		switch s.him {
-		case nsNo:
+		case NegNo:
 			if s.opt.LetHim() {
 				t.indicate(do, code)
-				s.him = nsYes
+				s.him = NegYes
 
 				slog.Debug("option enabled for him", "opt", s.opt)
 				callback, enabled = s.opt.SetHim, true
 			} else {
 				t.indicate(dont, code)
 			}
-		case nsYes:
+		case NegYes:
 			// Ignore
-		case nsWantNo:
-			err = fmt.Errorf("%s option %s answered by %s", dont, s.opt, will)
-			s.him = nsNo
+		case NegWantNo:
+			err = &NegotiationError{Opt: s.opt, State: s.him, Cmd: cmd}
+			s.him = NegNo
 
 			slog.Debug("option disabled for him", "opt", s.opt)
 			callback, enabled = s.opt.SetHim, false
-		case nsWantNoOpp:
-			err = fmt.Errorf("%s option %s answered by %s", dont, s.opt, will)
+		case NegWantNoOpp:
+			err = &NegotiationError{Opt: s.opt, State: s.him, Cmd: cmd}
 			fallthrough
-		case nsWantYes:
-			s.him = nsYes
+		case NegWantYes:
+			s.him = NegYes
 
 			slog.Debug("option enabled for him", "opt", s.opt)
 			callback, enabled = s.opt.SetHim, true
-		case nsWantYesOpp:
+		case NegWantYesOpp:
 			t.indicate(dont, code)
-			s.him = nsWantNo
+			s.him = NegWantNo
 		}
 	case wont:
 		// He is indicating that he is disabling an option, accepting our
 		// request that he disable an option, or refusing our request for him
 		// to enable an option.
 		switch s.him {
-		case nsNo:
+		case NegNo:
 			// Ignore
-		case nsYes:
+		case NegYes:
 			t.indicate(dont, code)
 			fallthrough
-		case nsWantNo, nsWantYes, nsWantYesOpp:
-			s.him = nsNo
+		case NegWantNo, NegWantYes, NegWantYesOpp:
+			s.him = NegNo
 
 			slog.Debug("option disabled for him", "opt", s.opt)
 			callback, enabled = s.opt.SetHim, false
-		case nsWantNoOpp:
+		case NegWantNoOpp:
 			t.indicate(do, code)
-			s.him = nsWantYes
+			s.him = NegWantYes
 		}
 	case do:
 		// He is accepting our request for us to enable an option or asking us
 		// to enable an option.
 		switch s.us {
-		case nsNo:
+		case NegNo:
 			if s.opt.LetUs() {
 				t.indicate(will, code)
-				s.us = nsYes
+				s.us = NegYes
 
 				slog.Debug("option enabled for us", "opt", s.opt)
 				callback, enabled = s.opt.SetUs, true
 			} else {
 				t.indicate(wont, code)
 			}
-		case nsYes:
+		case NegYes:
 			// Ignore
-		case nsWantNo:
-			err = fmt.Errorf("%s option %s answered by %s", wont, s.opt, do)
-			s.us = nsNo
+		case NegWantNo:
+			err = &NegotiationError{Opt: s.opt, State: s.us, Cmd: cmd}
+			s.us = NegNo
 
 			slog.Debug("option disabled for us", "opt", s.opt)
 			callback, enabled = s.opt.SetUs, false
-		case nsWantNoOpp:
-			err = fmt.Errorf("%s option %s answered by %s", wont, s.opt, do)
+		case NegWantNoOpp:
+			err = &NegotiationError{Opt: s.opt, State: s.us, Cmd: cmd}
 			fallthrough
-		case nsWantYes:
-			s.us = nsYes
+		case NegWantYes:
+			s.us = NegYes
 
 			slog.Debug("option enabled for us", "opt", s.opt)
 			callback, enabled = s.opt.SetUs, true
-		case nsWantYesOpp:
+		case NegWantYesOpp:
 			t.indicate(wont, code)
-			s.us = nsWantNo
+			s.us = NegWantNo
 
 		}
 	case dont:
 		// He is refusing our request for us to enable an option or asking us
 		// to disable an option.
 		switch s.us {
-		case nsNo:
+		case NegNo:
 			// Ignore
-		case nsYes:
+		case NegYes:
 			t.indicate(wont, code)
 			fallthrough
-		case nsWantNo, nsWantYes, nsWantYesOpp:
-			s.us = nsNo
+		case NegWantNo, NegWantYes, NegWantYesOpp:
+			s.us = NegNo
 
 			slog.Debug("option disabled for us", "opt", s.opt)
 			callback, enabled = s.opt.SetUs, false
-		case nsWantNoOpp:
+		case NegWantNoOpp:
 			t.indicate(will, code)
-			s.us = nsWantYes
+			s.us = NegWantYes
 		}
 	}
 
+	t.changed(old, s)
 	t.os.store(s)
+	t.watch(code)
 
 	if callback != nil {
 		t.mu.Unlock()
@@ -225,9 +280,38 @@ func (t *Ctx) negotiate(cmd Command, code byte) (err error) {
 	return
 }
 
+// param appends a subnegotiation option code or parameter byte to the
+// command buffer unless the maximum length for the option has been
+// exceeded.
+func (t *Ctx) param(b byte) (err error) {
+	if t.overflow {
+		return
+	}
+	if len(t.cb) == 0 {
+		t.cb = append(t.cb, b)
+		return
+	}
+
+	s := t.os.load(t.cb[0])
+	max := t.maxParams
+	if l, ok := s.opt.(ParamsLimiter); ok {
+		max = l.MaxParams()
+	}
+	if max > 0 && len(t.cb)-1 >= max {
+		t.overflow = true
+		t.cb = t.cb[:1]
+		return &ParamsLenError{Opt: s.opt, Max: max}
+	}
+
+	t.cb = append(t.cb, b)
+
+	return
+}
+
 func (t *Ctx) subnegotiate(code byte, params []byte) {
 	s := t.os.load(code)
 	slog.Debug("subnegotiation", "opt", s.opt, "params", hex.Dump(params))
+	t.emitParams(EventParams, s.opt, params)
 
 	t.mu.Unlock()
 	s.opt.Params(t, params)
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+// paramsOpt is an option that records the subnegotiation parameters it
+// receives.
+type paramsOpt struct {
+	AcceptOpt
+	params []string
+}
+
+func (o *paramsOpt) Params(tn *Ctx, params []byte) {
+	o.params = append(o.params, string(params))
+}
+
+// limitOpt is a paramsOpt that limits the length of its parameters.
+type limitOpt struct {
+	paramsOpt
+	max int
+}
+
+func (o *limitOpt) MaxParams() int { return o.max }
+
+func TestParamsLimit(t *testing.T) {
+	const in = "a\xff\xfa\x18hello\xff\xffworld\xff\xf0b\xff\xfa\x18hi\xff\xf0c\xff\xfa\xff\xf0d"
+
+	tests := []struct {
+		name  string
+		max   int
+		opt   Option
+		want  []string
+		limit int
+	}{
+		{"default", DefaultMaxParams, &paramsOpt{}, []string{"hello\xffworld", "hi"}, 0},
+		{"unlimited", 0, &paramsOpt{}, []string{"hello\xffworld", "hi"}, 0},
+		{"exceeded", 5, &paramsOpt{}, []string{"hi"}, 5},
+		{"limiter", 20, &limitOpt{max: 2}, []string{"hi"}, 2},
+	}
+
+	for _, test := range tests {
+		var params *paramsOpt
+		switch o := test.opt.(type) {
+		case *paramsOpt:
+			o.Code, params = 24, o
+		case *limitOpt:
+			o.Code, params = 24, &o.paramsOpt
+		}
+
+		tn := NewReadWriter(newRWBuf(in), test.opt)
+		tn.SetMaxParams(test.max)
+		var errs []error
+		tn.HandleErr(func(_ *Ctx, err error) { errs = append(errs, err) })
+
+		b, err := io.ReadAll(tn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "abcd" {
+			t.Errorf("%s: read %q, want %q", test.name, b, "abcd")
+		}
+		if !slices.Equal(params.params, test.want) {
+			t.Errorf("%s: params %q, want %q", test.name, params.params, test.want)
+		}
+
+		var lerr *ParamsLenError
+		switch {
+		case test.limit == 0 && len(errs) > 0:
+			t.Errorf("%s: unexpected errors %v", test.name, errs)
+		case test.limit > 0 && (len(errs) != 1 || !errors.As(errs[0], &lerr) || lerr.Max != test.limit):
+			t.Errorf("%s: errors %v, want a ParamsLenError with max %d", test.name, errs, test.limit)
+		}
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	const in = "\xff\xfb\x18a\xff\xfb\x18b\xff\xfa\x18x\xff\x01\xff\xf0c"
+
+	for _, fatal := range []bool{false, true} {
+		opt := &paramsOpt{AcceptOpt: AcceptOpt{Code: 24}}
+		tn := NewReadWriter(newRWBuf(in), opt)
+		tn.SetFatal(fatal)
+		var errs []error
+		tn.HandleErr(func(_ *Ctx, err error) { errs = append(errs, err) })
+
+		// He enables the option, then answers our request to disable it by
+		// enabling it again, and then sends an unexpected command within
+		// subnegotiation parameters.
+		var data []byte
+		b := make([]byte, 1)
+		n, _ := tn.Read(b)
+		data = append(data, b[:n]...)
+		tn.AskHim(opt, false)
+
+		var rerrs []error
+		for {
+			n, err := tn.Read(b)
+			data = append(data, b[:n]...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				rerrs = append(rerrs, err)
+			}
+		}
+
+		if string(data) != "abc" {
+			t.Errorf("fatal %v: read %q, want %q", fatal, data, "abc")
+		}
+
+		var nerr *NegotiationError
+		var perr *ParamsError
+		if len(errs) != 2 || !errors.As(errs[0], &nerr) || !errors.As(errs[1], &perr) {
+			t.Fatalf("fatal %v: errors %v, want a NegotiationError and ParamsError", fatal, errs)
+		}
+		if nerr.State != NegWantNo || nerr.Cmd != will {
+			t.Errorf("fatal %v: negotiation error %+v", fatal, nerr)
+		}
+		if perr.Byte != 1 {
+			t.Errorf("fatal %v: params error byte %d, want 1", fatal, perr.Byte)
+		}
+
+		if fatal && !slices.Equal(rerrs, errs) {
+			t.Errorf("Read errors %v, want %v", rerrs, errs)
+		} else if !fatal && len(rerrs) > 0 {
+			t.Errorf("unexpected Read errors %v", rerrs)
+		}
+	}
+}
@@ -6,6 +6,29 @@ package telnet
 
 import "fmt"
 
+// AcceptOpt is an option that either side is allowed to enable and that
+// ignores subnegotiation.  It's useful for accepting unknown options.
+type AcceptOpt struct {
+	Code byte
+	Name string
+}
+
+func (a AcceptOpt) Byte() byte { return a.Code }
+func (a AcceptOpt) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("Unknown-%d", a.Code)
+}
+
+func (AcceptOpt) LetHim() bool { return true }
+func (AcceptOpt) LetUs() bool  { return true }
+
+func (AcceptOpt) Params(tn *Ctx, params []byte) {}
+
+func (AcceptOpt) SetHim(tn *Ctx, enabled bool) {}
+func (AcceptOpt) SetUs(tn *Ctx, enabled bool)  {}
+
 // noOpt is used when unknown options are encountered during negotiation.
 type noOpt struct {
 	Code byte
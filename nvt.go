@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+// SetNVT enables or disables Network Virtual Terminal end of line
+// translation.
+//
+// When enabled, a received CR LF is translated to "\n" and CR NUL to "\r".
+// A received CR is held until the following byte arrives, or reading
+// fails, since it determines the translation.
+//
+// When written, "\n" is translated to CR LF and a bare "\r" to CR NUL.  The
+// NUL following a "\r" that ends a write is held until the next write in
+// case it starts with "\n".
+//
+// Translation is bypassed in each direction that Binary Transmission is
+// enabled.
+func (t *Ctx) SetNVT(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nvt = enabled
+}
+
+// nvtHim indicates if received data should be translated.
+func (t *Ctx) nvtHim() bool {
+	return t.nvt && t.os.load(optBinary).him != NegYes
+}
+
+// nvtUs indicates if written data should be translated.
+func (t *Ctx) nvtUs() bool {
+	return t.nvt && t.os.load(optBinary).us != NegYes
+}
+
+// nvtEncode translates end of lines to their NVT representation.  A
+// trailing "\r" is written without its NUL, which is held until the next
+// write, since a "\n" at the start of the next write completes an end of
+// line.
+func (t *Ctx) nvtEncode(b []byte) []byte {
+	buf := make([]byte, 0, len(b)+1)
+	if t.wcr {
+		t.wcr = false
+		if len(b) > 0 && b[0] == '\n' {
+			buf = append(buf, '\n')
+			b = b[1:]
+		} else {
+			buf = append(buf, 0)
+		}
+	}
+
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\r' && i+1 == len(b):
+			buf = append(buf, '\r')
+			t.wcr = true
+		case b[i] == '\r' && b[i+1] == '\n':
+			buf = append(buf, '\r', '\n')
+			i++
+		case b[i] == '\r':
+			buf = append(buf, '\r', 0)
+		case b[i] == '\n':
+			buf = append(buf, '\r', '\n')
+		default:
+			buf = append(buf, b[i])
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"io"
+	"testing"
+)
+
+func TestNVTRead(t *testing.T) {
+	tests := []struct {
+		name string
+		nvt  bool
+		opts []Option
+		in   string
+		want string
+	}{
+		{"disabled", false, nil, "a\r\nb\r\x00c", "a\r\nb\r\x00c"},
+		{"enabled", true, nil, "a\r\nb\r\x00c\r\rd\r\xff\xf1e", "a\nb\rc\r\rd\re"},
+		{"trailing", true, nil, "abc\r", "abc\r"},
+		{"binary", true, []Option{AcceptOpt{Code: optBinary, Name: "Binary"}}, "\xff\xfb\x00a\r\nb\r\x00c", "a\r\nb\r\x00c"},
+	}
+
+	for _, test := range tests {
+		tn := NewReadWriter(newRWBuf(test.in), test.opts...)
+		tn.SetNVT(test.nvt)
+
+		b, err := io.ReadAll(tn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("%s: read %q, want %q", test.name, b, test.want)
+		}
+	}
+}
+
+func TestNVTWrite(t *testing.T) {
+	tests := []struct {
+		writes []string
+		want   string
+	}{
+		{[]string{"a\nb\r\nc\rd"}, "a\r\nb\r\nc\r\x00d"},
+		{[]string{"a\r", "\n"}, "a\r\n"},
+		{[]string{"a\r", "b"}, "a\r\x00b"},
+		{[]string{"a\r", "\r", "\n"}, "a\r\x00\r\n"},
+	}
+
+	for _, test := range tests {
+		rw := newRWBuf("")
+		tn := NewReadWriter(rw)
+		tn.SetNVT(true)
+
+		for _, s := range test.writes {
+			tn.Write([]byte(s))
+		}
+		if rw.out.String() != test.want {
+			t.Errorf("%q: wrote %q, want %q", test.writes, rw.out.String(), test.want)
+		}
+	}
+}
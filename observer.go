@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import "slices"
+
+//go:generate stringer -type EventKind -trimprefix Event
+
+// EventKind is the kind of a negotiation event.
+type EventKind byte
+
+// Negotiation event kinds.
+const (
+	EventSent       EventKind = iota // Option command sent
+	EventReceived                    // Option command received
+	EventState                       // Option state changed
+	EventParamsSent                  // Subnegotiation parameters sent
+	EventParams                      // Subnegotiation parameters received
+	EventCmdSent                     // Control command sent
+	EventCmd                         // Control command received
+	EventTimeout                     // Option negotiation timed out
+)
+
+// Event is a negotiation event.  Fields that don't apply to the kind of
+// event are zero.
+type Event struct {
+	Kind     EventKind
+	Cmd      Command  // Sent or received command
+	Opt      Option   // Option, if any
+	Side     Side     // Side of a state change or timeout
+	From, To NegState // State change
+	Params   []byte   // Subnegotiation parameters
+}
+
+// Observer receives negotiation events.
+type Observer interface {
+	// Observe is called synchronously with the context locked so it must
+	// not call any of its methods.
+	Observe(tn *Ctx, e Event)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as
+// observers.
+type ObserverFunc func(tn *Ctx, e Event)
+
+// Observe calls f(tn, e).
+func (f ObserverFunc) Observe(tn *Ctx, e Event) { f(tn, e) }
+
+// SetObserver sets the observer that receives negotiation events.  A nil
+// observer removes it.
+func (t *Ctx) SetObserver(o Observer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.obs = o
+}
+
+// emit sends an event to the observer.
+func (t *Ctx) emit(e Event) {
+	if t.obs != nil {
+		t.obs.Observe(t, e)
+	}
+}
+
+// changed emits state change events for each side of an option that
+// differs.
+func (t *Ctx) changed(old, s optState) {
+	if t.obs == nil {
+		return
+	}
+
+	if old.him != s.him {
+		t.emit(Event{Kind: EventState, Opt: s.opt, Side: Him, From: old.him, To: s.him})
+	}
+	if old.us != s.us {
+		t.emit(Event{Kind: EventState, Opt: s.opt, Side: Us, From: old.us, To: s.us})
+	}
+}
+
+// emitParams emits a subnegotiation event.
+func (t *Ctx) emitParams(kind EventKind, opt Option, params []byte) {
+	if t.obs != nil {
+		t.emit(Event{Kind: kind, Opt: opt, Params: slices.Clone(params)})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestObserver(t *testing.T) {
+	opt := AcceptOpt{Code: 24, Name: "Terminal-Type"}
+	willTerm := string([]byte{byte(iac), byte(will), 24})
+
+	rw := newRWBuf(willTerm + iacCmd(AYT))
+	tn := NewReadWriter(rw, opt)
+
+	var events []Event
+	tn.SetObserver(ObserverFunc(func(tn *Ctx, e Event) { events = append(events, e) }))
+
+	if err := tn.AskHim(opt, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Event{
+		{Kind: EventSent, Cmd: do, Opt: opt},
+		{Kind: EventState, Opt: opt, Side: Him, From: NegNo, To: NegWantYes},
+		{Kind: EventReceived, Cmd: will, Opt: opt},
+		{Kind: EventState, Opt: opt, Side: Him, From: NegWantYes, To: NegYes},
+		{Kind: EventCmd, Cmd: AYT},
+		{Kind: EventCmdSent, Cmd: GA},
+	}
+	if !slices.EqualFunc(events, want, func(a, b Event) bool {
+		return a.Kind == b.Kind && a.Cmd == b.Cmd && a.Opt == b.Opt &&
+			a.Side == b.Side && a.From == b.From && a.To == b.To
+	}) {
+		t.Errorf("events %+v, want %+v", events, want)
+	}
+
+	// Removing the observer stops events.
+	events = nil
+	tn.SetObserver(nil)
+	tn.AskHim(opt, false)
+	if len(events) != 0 {
+		t.Errorf("events %+v after removing observer, want none", events)
+	}
+}
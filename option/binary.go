@@ -0,0 +1,26 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import "github.com/ebarkie/telnet"
+
+// Binary is the RFC856 Telnet Binary Transmission Option.
+//
+// The context bypasses NVT translation in each direction that it's
+// enabled.
+type Binary struct {
+	Us, Him bool
+}
+
+func (Binary) Byte() byte     { return 0 }
+func (Binary) String() string { return "Binary Transmission" }
+
+func (Binary) LetHim() bool { return true }
+func (Binary) LetUs() bool  { return true }
+
+func (Binary) Params(tn *telnet.Ctx, params []byte) {}
+
+func (b *Binary) SetHim(tn *telnet.Ctx, enabled bool) { b.Him = enabled }
+func (b *Binary) SetUs(tn *telnet.Ctx, enabled bool)  { b.Us = enabled }
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+// Environment subnegotiation commands.
+const (
+	envIs   byte = 0
+	envSend byte = 1
+	envInfo byte = 2
+)
+
+// Environment variable type codes.
+const (
+	envVar     byte = 0
+	envValue   byte = 1
+	envEsc     byte = 2
+	envUserVar byte = 3
+)
+
+// Env is a set of environment variables.
+type Env map[string]string
+
+// wellKnown are the well-known variables, which are sent as VAR.  All
+// other variables are sent as USERVAR, if the encoding has it.
+var wellKnown = map[string]bool{
+	"USER":       true,
+	"JOB":        true,
+	"ACCT":       true,
+	"PRINTER":    true,
+	"SYSTEMTYPE": true,
+	"DISPLAY":    true,
+}
+
+// envEntry is a variable in an environment list.  A variable that's not
+// defined has no value, which is different from an empty value.
+type envEntry struct {
+	name    string
+	user    bool
+	value   string
+	defined bool
+}
+
+// envCodec encodes and decodes environment lists using its VAR, VALUE,
+// and USERVAR type codes.  An encoding without USERVAR uses the VAR code
+// for it.
+type envCodec struct {
+	vr, value, uservar byte
+}
+
+var (
+	envStdCodec     = envCodec{vr: envVar, value: envValue, uservar: envUserVar}
+	envOldCodec     = envCodec{vr: envVar, value: envValue, uservar: envVar}
+	envSwappedCodec = envCodec{vr: envValue, value: envVar, uservar: envValue}
+)
+
+// user indicates if a variable is sent as USERVAR.
+func (c envCodec) user(name string) bool {
+	return c.uservar != c.vr && !wellKnown[name]
+}
+
+// decode decodes an environment list.  Bytes preceding the first VAR or
+// USERVAR are ignored.
+func (c envCodec) decode(params []byte) (entries []envEntry) {
+	var name, value []byte
+	cur := -1
+	flush := func() {
+		if cur >= 0 {
+			entries[cur].name, entries[cur].value = string(name), string(value)
+		}
+	}
+
+	for i := 0; i < len(params); i++ {
+		b := params[i]
+		switch b {
+		case c.vr, envUserVar:
+			flush()
+			entries = append(entries, envEntry{user: b == envUserVar})
+			cur, name, value = len(entries)-1, nil, nil
+			continue
+		case c.value:
+			if cur >= 0 {
+				entries[cur].defined = true
+			}
+			continue
+		case envEsc:
+			if i++; i >= len(params) {
+				continue
+			}
+			b = params[i]
+		}
+
+		switch {
+		case cur < 0:
+		case entries[cur].defined:
+			value = append(value, b)
+		default:
+			name = append(name, b)
+		}
+	}
+	flush()
+
+	return
+}
+
+// append encodes a variable and appends it to b.
+func (c envCodec) append(b []byte, e envEntry) []byte {
+	if e.user {
+		b = append(b, c.uservar)
+	} else {
+		b = append(b, c.vr)
+	}
+	b = envEscape(b, e.name)
+	if e.defined {
+		b = append(b, c.value)
+		b = envEscape(b, e.value)
+	}
+
+	return b
+}
+
+// envEscape appends s to b, escaping any bytes that are type codes.
+func envEscape(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] <= envUserVar {
+			b = append(b, envEsc)
+		}
+		b = append(b, s[i])
+	}
+
+	return b
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnvDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		c      envCodec
+		params string
+		want   []envEntry
+	}{
+		{"defined", envStdCodec, "\x00USER\x01bob", []envEntry{{name: "USER", value: "bob", defined: true}}},
+		{"undefined", envStdCodec, "\x00USER", []envEntry{{name: "USER"}}},
+		{"empty", envStdCodec, "\x00USER\x01", []envEntry{{name: "USER", defined: true}}},
+		{"uservar", envStdCodec, "\x03TERM\x01xterm", []envEntry{{name: "TERM", user: true, value: "xterm", defined: true}}},
+		{"list", envStdCodec, "\x00USER\x01bob\x03TERM\x00JOB\x01",
+			[]envEntry{{name: "USER", value: "bob", defined: true}, {name: "TERM", user: true}, {name: "JOB", defined: true}}},
+		{"all", envStdCodec, "\x00\x03", []envEntry{{}, {user: true}}},
+		{"escaped", envStdCodec, "\x03A\x02\x01B\x02\x03\x01x\x02\x00\x02\x02",
+			[]envEntry{{name: "A\x01B\x03", user: true, value: "x\x00\x02", defined: true}}},
+		{"trailing escape", envStdCodec, "\x00A\x02", []envEntry{{name: "A"}}},
+		{"leading", envStdCodec, "junk\x01\x00A", []envEntry{{name: "A"}}},
+		{"none", envStdCodec, "", nil},
+		{"swapped", envSwappedCodec, "\x01USER\x00bob\x03TERM",
+			[]envEntry{{name: "USER", value: "bob", defined: true}, {name: "TERM", user: true}}},
+	}
+
+	for _, test := range tests {
+		if entries := test.c.decode([]byte(test.params)); !slices.Equal(entries, test.want) {
+			t.Errorf("%s: decoded %+v, want %+v", test.name, entries, test.want)
+		}
+	}
+}
+
+func TestEnvAppend(t *testing.T) {
+	tests := []struct {
+		name string
+		c    envCodec
+		e    envEntry
+		want string
+	}{
+		{"defined", envStdCodec, envEntry{name: "USER", value: "bob", defined: true}, "\x00USER\x01bob"},
+		{"undefined", envStdCodec, envEntry{name: "USER"}, "\x00USER"},
+		{"empty", envStdCodec, envEntry{name: "USER", defined: true}, "\x00USER\x01"},
+		{"uservar", envStdCodec, envEntry{name: "TERM", user: true, value: "xterm", defined: true}, "\x03TERM\x01xterm"},
+		{"all", envStdCodec, envEntry{}, "\x00"},
+		{"escaped", envStdCodec, envEntry{name: "A\x01B\x03", value: "x\x00\x02\x04", defined: true},
+			"\x00A\x02\x01B\x02\x03\x01x\x02\x00\x02\x02\x04"},
+		{"swapped", envSwappedCodec, envEntry{name: "USER", value: "bob", defined: true}, "\x01USER\x00bob"},
+	}
+
+	for _, test := range tests {
+		b := test.c.append([]byte{envIs}, test.e)
+		if want := "\x00" + test.want; string(b) != want {
+			t.Errorf("%s: encoded %q, want %q", test.name, b, want)
+		}
+
+		// It decodes to the same entry.
+		if entries := test.c.decode(b[1:]); len(entries) != 1 || entries[0] != test.e {
+			t.Errorf("%s: decoded %+v, want %+v", test.name, entries, test.e)
+		}
+	}
+}
+
+func TestEnvEscape(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"\x00\x01\x02\x03", "\x02\x00\x02\x01\x02\x02\x02\x03"},
+		{"a\x04\xff", "a\x04\xff"},
+	}
+
+	for _, test := range tests {
+		if b := envEscape([]byte("x"), test.s); string(b) != "x"+test.want {
+			t.Errorf("%q: escaped %q, want %q", test.s, b, "x"+test.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"encoding/binary"
+
+	"github.com/ebarkie/telnet"
+)
+
+// Size is a window size in characters.
+type Size struct {
+	Width, Height uint16
+}
+
+// params encodes the size as subnegotiation parameters.
+func (s Size) params() []byte {
+	return binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(nil, s.Width), s.Height)
+}
+
+// NAWS is the RFC1073 Telnet Window Size Option.
+type NAWS struct {
+	// Him is the window size he reported.  A zero dimension is unknown.
+	Him Size
+	// Us is our window size that's reported to him.  We are only willing to
+	// enable the option if it's not zero.
+	Us Size
+
+	// OnResize is called each time he reports his window size.
+	OnResize func(tn *telnet.Ctx, size Size)
+}
+
+func (NAWS) Byte() byte     { return 31 }
+func (NAWS) String() string { return "Negotiate About Window Size" }
+
+func (NAWS) LetHim() bool  { return true }
+func (n NAWS) LetUs() bool { return n.Us != Size{} }
+
+func (n *NAWS) Params(tn *telnet.Ctx, params []byte) {
+	if len(params) != 4 {
+		return
+	}
+
+	n.Him = Size{
+		Width:  binary.BigEndian.Uint16(params[0:2]),
+		Height: binary.BigEndian.Uint16(params[2:4]),
+	}
+	if n.OnResize != nil {
+		n.OnResize(tn, n.Him)
+	}
+}
+
+func (*NAWS) SetHim(tn *telnet.Ctx, enabled bool) {}
+func (n *NAWS) SetUs(tn *telnet.Ctx, enabled bool) {
+	if enabled {
+		tn.SendParams(n, n.Us.params())
+	}
+}
+
+// Resize updates our window size and reports it to him if the option is
+// enabled.
+func (n *NAWS) Resize(tn *telnet.Ctx, size Size) {
+	n.Us = size
+	if tn.Enabled(n, telnet.Us) {
+		tn.SendParams(n, n.Us.params())
+	}
+}
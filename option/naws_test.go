@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/ebarkie/telnet"
+)
+
+func TestNAWSParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []byte
+		want   []Size
+	}{
+		{"size", []byte{0, 80, 0, 24}, []Size{{80, 24}}},
+		{"iac", []byte{0, 255, 1, 0}, []Size{{255, 256}}},
+		{"unknown", []byte{0, 0, 0, 0}, []Size{{0, 0}}},
+		{"short", []byte{0, 80, 0}, nil},
+		{"long", []byte{0, 80, 0, 24, 0}, nil},
+		{"empty", nil, nil},
+	}
+
+	for _, test := range tests {
+		var sizes []Size
+		n := &NAWS{Him: Size{1, 1}, OnResize: func(tn *telnet.Ctx, size Size) { sizes = append(sizes, size) }}
+		run(t, n, cmd(will, n)+params(n, test.params...))
+
+		if !slices.Equal(sizes, test.want) {
+			t.Errorf("%s: resized %v, want %v", test.name, sizes, test.want)
+		}
+		want := Size{1, 1}
+		if len(test.want) > 0 {
+			want = test.want[0]
+		}
+		if n.Him != want {
+			t.Errorf("%s: size %v, want %v", test.name, n.Him, want)
+		}
+	}
+}
+
+func TestNAWSResize(t *testing.T) {
+	n := &NAWS{Us: Size{80, 24}}
+
+	// Our size is reported once we enable the option.
+	rw := newRWBuf(cmd(do, n))
+	tn := telnet.NewReadWriter(rw, n)
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+	if want := cmd(will, n) + params(n, 0, 80, 0, 24); rw.out.String() != want {
+		t.Errorf("wrote %q, want %q", rw.out.String(), want)
+	}
+
+	// Changes are reported while it's enabled.
+	rw.out.Reset()
+	n.Resize(tn, Size{255, 511})
+	if want := params(n, 0, 255, 1, 255); rw.out.String() != want {
+		t.Errorf("resize wrote %q, want %q", rw.out.String(), want)
+	}
+	if n.Us != (Size{255, 511}) {
+		t.Errorf("size %v, want %v", n.Us, Size{255, 511})
+	}
+
+	// Changes are only recorded while it's disabled.
+	n = &NAWS{}
+	rw = newRWBuf("")
+	tn = telnet.NewReadWriter(rw, n)
+	if n.LetUs() {
+		t.Error("willing to enable without a size")
+	}
+	n.Resize(tn, Size{100, 50})
+	if rw.out.Len() != 0 {
+		t.Errorf("resize wrote %q while disabled", rw.out.String())
+	}
+	if !n.LetUs() {
+		t.Error("not willing to enable with a size")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/ebarkie/telnet"
+)
+
+// NewEnviron is the RFC1572 Telnet Environment Option.
+//
+// When he enables the option his variables are requested and he may
+// later report changes using INFO.  When we enable the option we answer
+// his requests from our environment and report our changes using INFO.
+type NewEnviron struct {
+	// Vars and UserVars are the names of the VAR and USERVAR variables
+	// requested from him.  An empty name requests all variables of that
+	// type and if both are empty then all variables are requested.
+	Vars, UserVars []string
+	// Him is the environment he reported.
+	Him Env
+	// OnChange is called each time he reports a variable that has changed.
+	// If it's no longer defined then ok is false.
+	OnChange func(tn *telnet.Ctx, name, value string, ok bool)
+
+	// Us is our environment that's reported to him.  We are only willing
+	// to enable the option if it's not nil.
+	Us Env
+}
+
+func (NewEnviron) Byte() byte     { return 39 }
+func (NewEnviron) String() string { return "New Environment" }
+
+func (NewEnviron) LetHim() bool  { return true }
+func (e NewEnviron) LetUs() bool { return e.Us != nil }
+
+func (e *NewEnviron) Params(tn *telnet.Ctx, params []byte) {
+	e.params(tn, e, envStdCodec, params)
+}
+
+// params processes subnegotiation parameters for opt.
+func (e *NewEnviron) params(tn *telnet.Ctx, opt telnet.Option, c envCodec, params []byte) {
+	if len(params) < 1 {
+		return
+	}
+
+	switch params[0] {
+	case envIs, envInfo:
+		e.update(tn, c.decode(params[1:]))
+	case envSend:
+		if e.LetUs() {
+			tn.SendParams(opt, e.reply(c, c.decode(params[1:])))
+		}
+	}
+}
+
+// update applies the variables he reported to his environment.
+func (e *NewEnviron) update(tn *telnet.Ctx, entries []envEntry) {
+	if e.Him == nil {
+		e.Him = Env{}
+	}
+
+	for _, v := range entries {
+		if v.name == "" {
+			continue
+		}
+
+		old, ok := e.Him[v.name]
+		if v.defined {
+			if ok && old == v.value {
+				continue
+			}
+			e.Him[v.name] = v.value
+		} else {
+			if !ok {
+				continue
+			}
+			delete(e.Him, v.name)
+		}
+
+		if e.OnChange != nil {
+			e.OnChange(tn, v.name, v.value, v.defined)
+		}
+	}
+}
+
+// reply returns IS parameters answering his request for variables.
+func (e *NewEnviron) reply(c envCodec, req []envEntry) []byte {
+	if len(req) == 0 {
+		req = []envEntry{{}, {user: true}}
+	}
+
+	b := []byte{envIs}
+	for _, r := range req {
+		if r.name != "" {
+			v, ok := e.Us[r.name]
+			b = c.append(b, envEntry{name: r.name, user: r.user, value: v, defined: ok})
+			continue
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(e.Us)) {
+			if c.user(name) == r.user {
+				b = c.append(b, envEntry{name: name, user: r.user, value: e.Us[name], defined: true})
+			}
+		}
+	}
+
+	return b
+}
+
+// request returns SEND parameters requesting his variables.
+func (e *NewEnviron) request(c envCodec) []byte {
+	b := []byte{envSend}
+	for _, name := range e.Vars {
+		b = c.append(b, envEntry{name: name})
+	}
+	for _, name := range e.UserVars {
+		b = c.append(b, envEntry{name: name, user: true})
+	}
+
+	return b
+}
+
+func (e *NewEnviron) SetHim(tn *telnet.Ctx, enabled bool) {
+	if enabled {
+		tn.SendParams(e, e.request(envStdCodec))
+	}
+}
+
+func (*NewEnviron) SetUs(tn *telnet.Ctx, enabled bool) {}
+
+// Setenv sets one of our variables and reports it to him if the option is
+// enabled.
+func (e *NewEnviron) Setenv(tn *telnet.Ctx, name, value string) {
+	e.setenv(tn, e, envStdCodec, envEntry{name: name, value: value, defined: true})
+}
+
+// Unsetenv removes one of our variables and reports it to him if the
+// option is enabled.
+func (e *NewEnviron) Unsetenv(tn *telnet.Ctx, name string) {
+	e.setenv(tn, e, envStdCodec, envEntry{name: name})
+}
+
+// setenv updates one of our variables and reports it to him using INFO.
+func (e *NewEnviron) setenv(tn *telnet.Ctx, opt telnet.Option, c envCodec, v envEntry) {
+	if e.Us == nil {
+		e.Us = Env{}
+	}
+	if v.defined {
+		e.Us[v.name] = v.value
+	} else {
+		delete(e.Us, v.name)
+	}
+
+	if tn.Enabled(opt, telnet.Us) {
+		v.user = c.user(v.name)
+		tn.SendParams(opt, c.append([]byte{envInfo}, v))
+	}
+}
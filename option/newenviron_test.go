@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ebarkie/telnet"
+)
+
+// envParams returns environment subnegotiation parameters for a command
+// and list.
+func envParams(opt telnet.Option, cmd byte, list string) string {
+	return params(opt, append([]byte{cmd}, list...)...)
+}
+
+func TestNewEnvironReply(t *testing.T) {
+	us := Env{"USER": "bob", "TERM": "xterm", "EMPTY": ""}
+
+	tests := []struct {
+		name string
+		send string
+		want string
+	}{
+		{"all", "", "\x00USER\x01bob\x03EMPTY\x01\x03TERM\x01xterm"},
+		{"all var", "\x00", "\x00USER\x01bob"},
+		{"all uservar", "\x03", "\x03EMPTY\x01\x03TERM\x01xterm"},
+		{"all both", "\x03\x00", "\x03EMPTY\x01\x03TERM\x01xterm\x00USER\x01bob"},
+		{"named", "\x00USER\x03TERM", "\x00USER\x01bob\x03TERM\x01xterm"},
+		{"named empty", "\x03EMPTY", "\x03EMPTY\x01"},
+		{"named undefined", "\x00JOB\x03MISSING", "\x00JOB\x03MISSING"},
+		{"named other type", "\x03USER", "\x03USER\x01bob"},
+	}
+
+	for _, test := range tests {
+		e := &NewEnviron{Us: us}
+		out := run(t, e, cmd(do, e)+envParams(e, envSend, test.send))
+		if want := cmd(will, e) + envParams(e, envIs, test.want); out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+	}
+
+	// He can't request our variables unless we are willing to report them.
+	e := &NewEnviron{}
+	if out := run(t, e, envParams(e, envSend, "")); out != "" {
+		t.Errorf("wrote %q without an environment", out)
+	}
+}
+
+func TestNewEnvironRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		vars, userVars []string
+		want           string
+	}{
+		{"all", nil, nil, ""},
+		{"all var", []string{""}, nil, "\x00"},
+		{"all uservar", nil, []string{""}, "\x03"},
+		{"named", []string{"USER"}, []string{"TERM", "A\x01"}, "\x00USER\x03TERM\x03A\x02\x01"},
+	}
+
+	for _, test := range tests {
+		e := &NewEnviron{Vars: test.vars, UserVars: test.userVars}
+		out := run(t, e, cmd(will, e))
+		if want := cmd(do, e) + envParams(e, envSend, test.want); out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+	}
+}
+
+func TestNewEnvironUpdate(t *testing.T) {
+	type change struct {
+		name, value string
+		ok          bool
+	}
+
+	tests := []struct {
+		name    string
+		cmd     byte
+		list    string
+		want    Env
+		changes []change
+	}{
+		{"is", envIs, "\x00USER\x01bob\x03TERM\x01", Env{"USER": "bob", "TERM": ""},
+			[]change{{"USER", "bob", true}, {"TERM", "", true}}},
+		{"undefined", envIs, "\x03MISSING\x00USER\x01bob", Env{"USER": "bob"},
+			[]change{{"USER", "bob", true}}},
+		{"unnamed", envIs, "\x00\x01x\x03", Env{}, nil},
+		{"info unchanged", envInfo, "\x00USER\x01bob\x00USER\x01bob", Env{"USER": "bob"},
+			[]change{{"USER", "bob", true}}},
+		{"info changed", envInfo, "\x00USER\x01bob\x00USER\x01alice", Env{"USER": "alice"},
+			[]change{{"USER", "bob", true}, {"USER", "alice", true}}},
+		{"info empty", envInfo, "\x00USER\x01bob\x00USER\x01", Env{"USER": ""},
+			[]change{{"USER", "bob", true}, {"USER", "", true}}},
+		{"info removed", envInfo, "\x00USER\x01bob\x00USER", Env{},
+			[]change{{"USER", "bob", true}, {"USER", "", false}}},
+	}
+
+	for _, test := range tests {
+		var changes []change
+		e := &NewEnviron{OnChange: func(tn *telnet.Ctx, name, value string, ok bool) {
+			changes = append(changes, change{name, value, ok})
+		}}
+		run(t, e, envParams(e, test.cmd, test.list))
+
+		if !maps.Equal(e.Him, test.want) {
+			t.Errorf("%s: environment %q, want %q", test.name, e.Him, test.want)
+		}
+		if !slices.Equal(changes, test.changes) {
+			t.Errorf("%s: changes %+v, want %+v", test.name, changes, test.changes)
+		}
+	}
+}
+
+func TestNewEnvironSetenv(t *testing.T) {
+	e := &NewEnviron{Us: Env{}}
+
+	// Changes are only recorded while it's disabled.
+	rw := newRWBuf("")
+	tn := telnet.NewReadWriter(rw, e)
+	e.Setenv(tn, "USER", "bob")
+	if rw.out.Len() != 0 {
+		t.Errorf("wrote %q while disabled", rw.out.String())
+	}
+
+	// Changes are reported using INFO while it's enabled.
+	rw = newRWBuf(cmd(do, e))
+	tn = telnet.NewReadWriter(rw, e)
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+	rw.out.Reset()
+	e.Setenv(tn, "TERM", "xterm")
+	e.Setenv(tn, "USER", "")
+	e.Unsetenv(tn, "TERM")
+
+	want := envParams(e, envInfo, "\x03TERM\x01xterm") +
+		envParams(e, envInfo, "\x00USER\x01") +
+		envParams(e, envInfo, "\x03TERM")
+	if rw.out.String() != want {
+		t.Errorf("wrote %q, want %q", rw.out.String(), want)
+	}
+	if want := (Env{"USER": ""}); !maps.Equal(e.Us, want) {
+		t.Errorf("environment %q, want %q", e.Us, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import "github.com/ebarkie/telnet"
+
+// OldEnviron is the RFC1408 Telnet Environment Option, which is superseded
+// by NEW-ENVIRON.
+//
+// Many implementations swapped the VAR and VALUE codes so, as described
+// by RFC1571, the encoding he uses is detected from the first type code
+// of each list he sends.  The variables are the same as NewEnviron except
+// there is no USERVAR so all of them are sent as VAR.
+type OldEnviron struct {
+	NewEnviron
+
+	// Swapped indicates he uses the swapped VAR and VALUE codes, which are
+	// then also used for lists sent to him.
+	Swapped bool
+}
+
+func (OldEnviron) Byte() byte     { return 36 }
+func (OldEnviron) String() string { return "Environment" }
+
+func (o *OldEnviron) Params(tn *telnet.Ctx, params []byte) {
+	if len(params) > 1 && (params[0] == envIs || params[0] == envSend || params[0] == envInfo) {
+		switch params[1] {
+		case envVar:
+			o.Swapped = false
+		case envValue:
+			o.Swapped = true
+		}
+	}
+
+	o.params(tn, o, o.codec(), params)
+}
+
+// codec returns the codec for the encoding he uses.
+func (o OldEnviron) codec() envCodec {
+	if o.Swapped {
+		return envSwappedCodec
+	}
+
+	return envOldCodec
+}
+
+func (o *OldEnviron) SetHim(tn *telnet.Ctx, enabled bool) {
+	if enabled {
+		tn.SendParams(o, o.request(o.codec()))
+	}
+}
+
+// Setenv sets one of our variables and reports it to him if the option is
+// enabled.
+func (o *OldEnviron) Setenv(tn *telnet.Ctx, name, value string) {
+	o.setenv(tn, o, o.codec(), envEntry{name: name, value: value, defined: true})
+}
+
+// Unsetenv removes one of our variables and reports it to him if the
+// option is enabled.
+func (o *OldEnviron) Unsetenv(tn *telnet.Ctx, name string) {
+	o.setenv(tn, o, o.codec(), envEntry{name: name})
+}
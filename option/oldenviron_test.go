@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"io"
+	"maps"
+	"testing"
+
+	"github.com/ebarkie/telnet"
+)
+
+func TestOldEnvironParams(t *testing.T) {
+	us := Env{"USER": "bob", "TERM": "xterm"}
+
+	tests := []struct {
+		name    string
+		swapped bool
+		in      string
+		want    string
+		swap    bool
+		him     Env
+	}{
+		{"is", true, "\x00\x00USER\x01bob", "", false, Env{"USER": "bob"}},
+		{"is swapped", false, "\x00\x01USER\x00bob", "", true, Env{"USER": "bob"}},
+		{"info", true, "\x02\x00USER\x01bob", "", false, Env{"USER": "bob"}},
+		{"info swapped", false, "\x02\x01USER\x00bob", "", true, Env{"USER": "bob"}},
+		{"send", true, "\x01\x00USER", "\x00\x00USER\x01bob", false, nil},
+		{"send swapped", false, "\x01\x01USER", "\x00\x01USER\x00bob", true, nil},
+		{"send all", false, "\x01", "\x00\x00TERM\x01xterm\x00USER\x01bob", false, nil},
+		{"send all swapped", true, "\x01", "\x00\x01TERM\x00xterm\x01USER\x00bob", true, nil},
+		{"send all var", false, "\x01\x00", "\x00\x00TERM\x01xterm\x00USER\x01bob", false, nil},
+		{"send uservar", false, "\x01\x03TERM", "\x00\x00TERM\x01xterm", false, nil},
+		{"uservar first", true, "\x00\x03TERM\x00xterm", "", true, Env{"TERM": "xterm"}},
+		{"unknown command", true, "\x05\x00USER", "", true, nil},
+	}
+
+	for _, test := range tests {
+		o := &OldEnviron{NewEnviron: NewEnviron{Us: us}, Swapped: test.swapped}
+		out := run(t, o, cmd(do, o)+params(o, []byte(test.in)...))
+
+		want := cmd(will, o)
+		if test.want != "" {
+			want += params(o, []byte(test.want)...)
+		}
+		if out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+		if o.Swapped != test.swap {
+			t.Errorf("%s: swapped %v, want %v", test.name, o.Swapped, test.swap)
+		}
+		if !maps.Equal(o.Him, test.him) {
+			t.Errorf("%s: environment %q, want %q", test.name, o.Him, test.him)
+		}
+	}
+}
+
+func TestOldEnvironRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		swapped bool
+		want    string
+	}{
+		{"standard", false, "\x01\x00USER\x00TERM"},
+		{"swapped", true, "\x01\x01USER\x01TERM"},
+	}
+
+	for _, test := range tests {
+		o := &OldEnviron{NewEnviron: NewEnviron{Vars: []string{"USER"}, UserVars: []string{"TERM"}}, Swapped: test.swapped}
+		out := run(t, o, cmd(will, o))
+		if want := cmd(do, o) + params(o, []byte(test.want)...); out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+	}
+}
+
+func TestOldEnvironSetenv(t *testing.T) {
+	o := &OldEnviron{NewEnviron: NewEnviron{Us: Env{}}}
+	rw := newRWBuf(cmd(do, o))
+	tn := telnet.NewReadWriter(rw, o)
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+	rw.out.Reset()
+
+	o.Setenv(tn, "TERM", "xterm")
+	o.Swapped = true
+	o.Setenv(tn, "USER", "bob")
+	o.Unsetenv(tn, "TERM")
+
+	want := params(o, []byte("\x02\x00TERM\x01xterm")...) +
+		params(o, []byte("\x02\x01USER\x00bob")...) +
+		params(o, []byte("\x02\x01TERM")...)
+	if rw.out.String() != want {
+		t.Errorf("wrote %q, want %q", rw.out.String(), want)
+	}
+}
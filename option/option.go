@@ -5,7 +5,15 @@
 // Package option implements several RFC855 Telnet Option
 // Specifications, including:
 //
+//  RFC856  Telnet Binary Transmission
 //  RFC857  Telnet Echo Option
 //  RFC858  Telnet Suppress Go Ahead Option
+//  RFC1073 Telnet Window Size Option
+//  RFC1079 Telnet Terminal Speed Option
 //  RFC1091 Telnet Terminal-Type Option
+//  RFC1408 Telnet Environment Option (see RFC1571)
+//  RFC1572 Telnet Environment Option
+//
+// as well as the START_TLS option from draft-altman-telnet-starttls and the
+// MUD Terminal Type Standard (MTTS) extension to Terminal-Type.
 package option
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ebarkie/telnet"
+)
+
+// Telnet command bytes used to build test input.
+const (
+	se   byte = 240
+	sb   byte = 250
+	will byte = 251
+	wont byte = 252
+	do   byte = 253
+	dont byte = 254
+	iac  byte = 255
+)
+
+// rwbuf is a ReadWriter that reads from a fixed input and records
+// writes.
+type rwbuf struct {
+	in  *bytes.Reader
+	out bytes.Buffer
+}
+
+func newRWBuf(in string) *rwbuf {
+	return &rwbuf{in: bytes.NewReader([]byte(in))}
+}
+
+func (rw *rwbuf) Read(b []byte) (int, error)  { return rw.in.Read(b) }
+func (rw *rwbuf) Write(b []byte) (int, error) { return rw.out.Write(b) }
+
+// cmd returns an option negotiation command.
+func cmd(c byte, opt telnet.Option) string {
+	return string([]byte{iac, c, opt.Byte()})
+}
+
+// params returns option subnegotiation parameters, escaping Interpret as
+// Command bytes.
+func params(opt telnet.Option, b ...byte) string {
+	b = bytes.ReplaceAll(b, []byte{iac}, []byte{iac, iac})
+	return string(append(append([]byte{iac, sb, opt.Byte()}, b...), iac, se))
+}
+
+// run reads all input through a context with the option available and
+// returns what was written.
+func run(t *testing.T, opt telnet.Option, in string) string {
+	t.Helper()
+
+	rw := newRWBuf(in)
+	tn := telnet.NewReadWriter(rw, opt)
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+
+	return rw.out.String()
+}
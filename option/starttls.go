@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/ebarkie/telnet"
+)
+
+// START_TLS subnegotiation commands.
+const startTLSFollows byte = 1
+
+// StartTLS is the Telnet START_TLS Option, which upgrades the connection
+// to TLS in-band.
+//
+// The server asks him to enable the option and sends FOLLOWS once he
+// does.  The client answers FOLLOWS and both then perform the TLS
+// handshake.
+type StartTLS struct {
+	// Config is the TLS configuration.  A server requires a certificate and
+	// a client typically requires a ServerName.
+	Config *tls.Config
+	// Client indicates we are the client, which performs the option, rather
+	// than the server, which requests it.
+	Client bool
+
+	// Active indicates the connection has been upgraded to TLS.
+	Active bool
+}
+
+func (StartTLS) Byte() byte     { return 46 }
+func (StartTLS) String() string { return "START_TLS" }
+
+func (s StartTLS) LetHim() bool { return !s.Client && !s.Active }
+func (s StartTLS) LetUs() bool  { return s.Client && !s.Active }
+
+func (s *StartTLS) Params(tn *telnet.Ctx, params []byte) {
+	if s.Active || len(params) < 1 || params[0] != startTLSFollows {
+		return
+	}
+
+	if s.Client {
+		if !tn.Enabled(s, telnet.Us) {
+			return
+		}
+		// The upgrade takes effect once the subnegotiation is parsed so
+		// FOLLOWS is still sent in the clear.
+		if err := tn.Upgrade(func(conn net.Conn) net.Conn { return tls.Client(conn, s.Config) }); err != nil {
+			return
+		}
+		tn.SendParams(s, []byte{startTLSFollows})
+	} else {
+		if !tn.Enabled(s, telnet.Him) {
+			return
+		}
+		if err := tn.Upgrade(func(conn net.Conn) net.Conn { return tls.Server(conn, s.Config) }); err != nil {
+			return
+		}
+	}
+	s.Active = true
+}
+
+func (s *StartTLS) SetHim(tn *telnet.Ctx, enabled bool) {
+	if enabled && !s.Client && !s.Active {
+		tn.SendParams(s, []byte{startTLSFollows})
+	}
+}
+
+func (*StartTLS) SetUs(tn *telnet.Ctx, enabled bool) {}
@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ebarkie/telnet"
+	"github.com/ebarkie/telnet/internal/testcert"
+)
+
+func TestStartTLS(t *testing.T) {
+	cert, pool := testcert.New(t, "localhost")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &telnet.Server{
+		Options: func() []telnet.Option {
+			return []telnet.Option{&StartTLS{Config: &tls.Config{Certificates: []tls.Certificate{cert}}}}
+		},
+		Negotiate: []telnet.Ask{{Opt: &StartTLS{}, Side: telnet.Him, Enable: true}},
+		Handler: telnet.HandlerFunc(func(s *telnet.Session) {
+			b := make([]byte, 4)
+			if _, err := io.ReadFull(s, b); err != nil {
+				t.Errorf("server read error %v", err)
+				return
+			}
+			if cs, ok := s.ConnectionState(); !ok || !cs.HandshakeComplete {
+				t.Errorf("server connection state %v, handshake complete %v", ok, cs.HandshakeComplete)
+			}
+			s.Write(append([]byte("pong "), b...))
+		}),
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	st := &StartTLS{Client: true, Config: &tls.Config{ServerName: "localhost", RootCAs: pool}}
+	tn, err := telnet.Dial("tcp", l.Addr().String(), st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tn.Close()
+
+	// Negotiate the option and upgrade before sending any data.
+	tn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for !st.Active {
+		if _, err := tn.Read(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tn.Write([]byte("ping"))
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "pong ping" {
+		t.Errorf("read %q, want %q", b, "pong ping")
+	}
+	if cs, ok := tn.ConnectionState(); !ok || !cs.HandshakeComplete {
+		t.Errorf("client connection state %v, handshake complete %v", ok, cs.HandshakeComplete)
+	}
+	if !tn.Enabled(st, telnet.Us) {
+		t.Error("START_TLS is not enabled for us")
+	}
+}
+
+func TestStartTLSRefused(t *testing.T) {
+	// A client without START_TLS refuses it and the session continues in
+	// the clear.
+	cert, _ := testcert.New(t, "localhost")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &telnet.Server{
+		Options: func() []telnet.Option {
+			return []telnet.Option{&StartTLS{Config: &tls.Config{Certificates: []tls.Certificate{cert}}}}
+		},
+		Negotiate: []telnet.Ask{{Opt: &StartTLS{}, Side: telnet.Him, Enable: true}},
+		Handler: telnet.HandlerFunc(func(s *telnet.Session) {
+			if s.Results[0].OK() {
+				t.Error("START_TLS negotiation succeeded")
+			}
+			if _, ok := s.ConnectionState(); ok {
+				t.Error("server session was upgraded to TLS")
+			}
+			s.Write([]byte("hello"))
+		}),
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	tn, err := telnet.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tn.Close()
+
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("read %q, want %q", b, "hello")
+	}
+}
+
+func TestStartTLSFollows(t *testing.T) {
+	follows := params(&StartTLS{}, startTLSFollows)
+
+	tests := []struct {
+		name   string
+		client bool
+		in     string
+		want   string
+	}{
+		{"client not enabled", true, follows, ""},
+		{"client not conn", true, cmd(do, &StartTLS{}) + follows, cmd(will, &StartTLS{})},
+		{"server not enabled", false, follows, ""},
+		{"server not conn", false, cmd(will, &StartTLS{}) + follows,
+			cmd(do, &StartTLS{}) + follows},
+	}
+
+	for _, test := range tests {
+		st := &StartTLS{Client: test.client}
+		if out := run(t, st, test.in); out != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, test.want)
+		}
+		if st.Active {
+			t.Errorf("%s: active", test.name)
+		}
+	}
+}
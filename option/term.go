@@ -4,33 +4,140 @@
 
 package option
 
-import "github.com/ebarkie/telnet"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ebarkie/telnet"
+)
+
+// Terminal-Type subnegotiation commands.
+const (
+	termIs   byte = 0
+	termSend byte = 1
+)
+
+// maxTermTypes is the maximum number of terminal types requested while
+// cycling, in case he never repeats one.
+const maxTermTypes = 16
+
+// MTTS is a MUD Terminal Type Standard capabilities bitvector.
+type MTTS uint
+
+// MUD Terminal Type Standard capabilities.
+const (
+	MTTSANSI            MTTS = 1 << iota // ANSI colors
+	MTTSVT100                            // VT100 interface
+	MTTSUTF8                             // UTF-8 character encoding
+	MTTS256Colors                        // 256 colors
+	MTTSMouseTracking                    // xterm mouse tracking
+	MTTSOSCColorPalette                  // OSC color palette
+	MTTSScreenReader                     // Screen reader in use
+	MTTSProxy                            // Proxy between the client and server
+	MTTSTrueColor                        // 24-bit colors
+	MTTSMNES                             // Mud New Environment Standard
+	MTTSMSLP                             // Mud Server Link Protocol
+	MTTSSSL                              // SSL/TLS
+)
+
+// Has indicates if all of the capabilities are present.
+func (m MTTS) Has(caps MTTS) bool { return m&caps == caps }
 
 // Term is the RFC1091 Telnet Terminal-Type Option.
+//
+// When he enables the option, SEND is repeated until he reports a
+// terminal type again, which indicates the end of his list.  If he
+// repeated his last type then SEND is sent once more so he wraps back to
+// his first, and preferred, type.
 type Term struct {
+	// Him is the first, and preferred, terminal type he reported.
 	Him string
+	// HimTypes is the list of terminal types he reported, in order.
+	HimTypes []string
+	// MTTS is the MUD Terminal Type Standard capabilities he reported, if
+	// any.
+	MTTS MTTS
+	// Done indicates he has reported his complete list.
+	Done bool
+	// wrap indicates he repeated his last terminal type and is being asked
+	// to wrap back to his first.
+	wrap bool
+
+	// Us is the list of terminal types we report, in order of preference.
+	// We are only willing to enable the option if it's not empty.
+	Us []string
+	// sent is the number of terminal types reported in the current cycle.
+	sent int
 }
 
 func (Term) Byte() byte     { return 24 }
 func (Term) String() string { return "Terminal-Type" }
 
-func (Term) LetHim() bool { return true }
-func (Term) LetUs() bool  { return false }
+func (Term) LetHim() bool  { return true }
+func (t Term) LetUs() bool { return len(t.Us) > 0 }
 
 func (t *Term) Params(tn *telnet.Ctx, params []byte) {
-	const is byte = 0
+	switch {
+	case len(params) > 1 && params[0] == termIs:
+		t.is(tn, string(params[1:]))
+	case len(params) > 0 && params[0] == termSend && len(t.Us) > 0:
+		t.send(tn)
+	}
+}
 
-	if len(params) > 1 && params[0] == is {
-		t.Him = string(params[1:])
+// is records a terminal type he reported and requests the next one until
+// he repeats.
+func (t *Term) is(tn *telnet.Ctx, name string) {
+	if t.Done {
+		return
+	}
+
+	if n := len(t.HimTypes); n > 0 {
+		switch {
+		case t.wrap || name == t.HimTypes[0]:
+			t.Done = true
+			return
+		case name == t.HimTypes[n-1]:
+			t.wrap = true
+			tn.SendParams(t, []byte{termSend})
+			return
+		}
+	}
+
+	t.HimTypes = append(t.HimTypes, name)
+	if len(t.HimTypes) == 1 {
+		t.Him = name
+	}
+	if caps, ok := strings.CutPrefix(name, "MTTS "); ok {
+		if n, err := strconv.ParseUint(caps, 10, 0); err == nil {
+			t.MTTS = MTTS(n)
+		}
+	}
+
+	if len(t.HimTypes) < maxTermTypes {
+		tn.SendParams(t, []byte{termSend})
+	} else {
+		t.Done = true
 	}
 }
 
-func (t Term) SetHim(tn *telnet.Ctx, enabled bool) {
-	const send byte = 1
+// send reports the next terminal type in our list.  The last type is
+// repeated to indicate the end of the list and then the cycle restarts.
+func (t *Term) send(tn *telnet.Ctx) {
+	name := t.Us[min(t.sent, len(t.Us)-1)]
+	t.sent++
+	if t.sent > len(t.Us) {
+		t.sent = 0
+	}
+
+	tn.SendParams(t, append([]byte{termIs}, name...))
+}
 
+func (t *Term) SetHim(tn *telnet.Ctx, enabled bool) {
 	if enabled {
-		tn.SendParams(&t, []byte{send})
+		t.HimTypes, t.MTTS, t.Done, t.wrap = nil, 0, false, false
+		tn.SendParams(t, []byte{termSend})
 	}
 }
 
-func (Term) SetUs(tn *telnet.Ctx, enabled bool) {}
+func (t *Term) SetUs(tn *telnet.Ctx, enabled bool) { t.sent = 0 }
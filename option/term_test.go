@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// termIsParams returns Terminal-Type IS parameters for each name.
+func termIsParams(names ...string) string {
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(params(&Term{}, append([]byte{termIs}, name...)...))
+	}
+
+	return b.String()
+}
+
+func TestTermCycle(t *testing.T) {
+	send := params(&Term{}, termSend)
+
+	tests := []struct {
+		name  string
+		in    []string
+		types []string
+		sends int
+		done  bool
+	}{
+		{"repeat last", []string{"A", "B", "C", "C", "A"}, []string{"A", "B", "C"}, 5, true},
+		{"repeat last waiting", []string{"A", "B", "C", "C"}, []string{"A", "B", "C"}, 5, false},
+		{"repeat last other", []string{"A", "B", "B", "C"}, []string{"A", "B"}, 4, true},
+		{"wrap", []string{"A", "B", "C", "A"}, []string{"A", "B", "C"}, 4, true},
+		{"single", []string{"A", "A"}, []string{"A"}, 2, true},
+		{"ignored once done", []string{"A", "A", "B"}, []string{"A"}, 2, true},
+		{"incomplete", []string{"A", "B"}, []string{"A", "B"}, 3, false},
+	}
+
+	for _, test := range tests {
+		term := &Term{}
+		out := run(t, term, cmd(will, term)+termIsParams(test.in...))
+
+		if n := strings.Count(out, send); n != test.sends {
+			t.Errorf("%s: sent %d SEND, want %d", test.name, n, test.sends)
+		}
+		if !slices.Equal(term.HimTypes, test.types) {
+			t.Errorf("%s: types %q, want %q", test.name, term.HimTypes, test.types)
+		}
+		if term.Him != test.types[0] {
+			t.Errorf("%s: type %q, want %q", test.name, term.Him, test.types[0])
+		}
+		if term.Done != test.done {
+			t.Errorf("%s: done %v, want %v", test.name, term.Done, test.done)
+		}
+	}
+}
+
+func TestTermMax(t *testing.T) {
+	var names []string
+	for i := range maxTermTypes + 1 {
+		names = append(names, string(rune('A'+i)))
+	}
+
+	term := &Term{}
+	out := run(t, term, cmd(will, term)+termIsParams(names...))
+
+	if n := strings.Count(out, params(term, termSend)); n != maxTermTypes {
+		t.Errorf("sent %d SEND, want %d", n, maxTermTypes)
+	}
+	if len(term.HimTypes) != maxTermTypes || !term.Done {
+		t.Errorf("types %d done %v, want %d true", len(term.HimTypes), term.Done, maxTermTypes)
+	}
+}
+
+func TestTermMTTS(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want MTTS
+	}{
+		{[]string{"XTERM", "XTERM-256COLOR", "MTTS 137", "MTTS 137"}, MTTSANSI | MTTS256Colors | MTTSProxy},
+		{[]string{"ANSI", "ANSI"}, 0},
+		{[]string{"MTTS", "MTTS"}, 0},
+		{[]string{"MTTS x", "MTTS x"}, 0},
+		{[]string{"MTTS -1", "MTTS -1"}, 0},
+	}
+
+	for _, test := range tests {
+		term := &Term{}
+		run(t, term, cmd(will, term)+termIsParams(test.in...))
+		if term.MTTS != test.want {
+			t.Errorf("%q: MTTS %d, want %d", test.in, term.MTTS, test.want)
+		}
+	}
+
+	caps := MTTSANSI | MTTSUTF8
+	if !caps.Has(MTTSANSI) || !caps.Has(MTTSANSI|MTTSUTF8) || caps.Has(MTTSANSI|MTTSVT100) {
+		t.Errorf("%d: Has is incorrect", caps)
+	}
+}
+
+func TestTermSend(t *testing.T) {
+	send := params(&Term{}, termSend)
+
+	tests := []struct {
+		name string
+		us   []string
+		want []string
+	}{
+		{"none", nil, nil},
+		{"single", []string{"X"}, []string{"X", "X", "X"}},
+		{"list", []string{"X", "Y"}, []string{"X", "Y", "Y", "X", "Y"}},
+	}
+
+	for _, test := range tests {
+		term := &Term{Us: test.us}
+		in := cmd(do, term) + strings.Repeat(send, max(len(test.want), 1))
+
+		want := cmd(wont, term)
+		if len(test.us) > 0 {
+			want = cmd(will, term) + termIsParams(test.want...)
+		}
+		if out := run(t, term, in); out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+	}
+}
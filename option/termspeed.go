@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ebarkie/telnet"
+)
+
+// Speed is a terminal speed in bits per second.  A zero speed is unknown.
+type Speed struct {
+	Transmit, Receive int
+}
+
+// params encodes the speed as IS subnegotiation parameters.
+func (s Speed) params() []byte {
+	b := append([]byte{termIs}, strconv.Itoa(s.Transmit)...)
+	b = append(b, ',')
+	return append(b, strconv.Itoa(s.Receive)...)
+}
+
+// TermSpeed is the RFC1079 Telnet Terminal Speed Option.
+type TermSpeed struct {
+	// Him is the terminal speed he reported.
+	Him Speed
+	// Us is our terminal speed that's reported to him.  We are only willing
+	// to enable the option if it's not zero.
+	Us Speed
+}
+
+func (TermSpeed) Byte() byte     { return 32 }
+func (TermSpeed) String() string { return "Terminal Speed" }
+
+func (TermSpeed) LetHim() bool  { return true }
+func (t TermSpeed) LetUs() bool { return t.Us != Speed{} }
+
+func (t *TermSpeed) Params(tn *telnet.Ctx, params []byte) {
+	switch {
+	case len(params) > 1 && params[0] == termIs:
+		tx, rx, ok := strings.Cut(string(params[1:]), ",")
+		if !ok {
+			return
+		}
+		var s Speed
+		var err error
+		if s.Transmit, err = strconv.Atoi(tx); err != nil {
+			return
+		}
+		if s.Receive, err = strconv.Atoi(rx); err != nil {
+			return
+		}
+		t.Him = s
+	case len(params) > 0 && params[0] == termSend && t.LetUs():
+		tn.SendParams(t, t.Us.params())
+	}
+}
+
+func (t *TermSpeed) SetHim(tn *telnet.Ctx, enabled bool) {
+	if enabled {
+		tn.SendParams(t, []byte{termSend})
+	}
+}
+
+func (*TermSpeed) SetUs(tn *telnet.Ctx, enabled bool) {}
@@ -0,0 +1,54 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package option
+
+import "testing"
+
+func TestTermSpeedParams(t *testing.T) {
+	tests := []struct {
+		name string
+		is   string
+		want Speed
+	}{
+		{"speed", "38400,19200", Speed{38400, 19200}},
+		{"unknown", "0,0", Speed{0, 0}},
+		{"no comma", "38400", Speed{1, 1}},
+		{"bad transmit", "x,19200", Speed{1, 1}},
+		{"bad receive", "38400,", Speed{1, 1}},
+		{"extra", "38400,19200,9600", Speed{1, 1}},
+		{"empty", "", Speed{1, 1}},
+	}
+
+	for _, test := range tests {
+		ts := &TermSpeed{Him: Speed{1, 1}}
+		out := run(t, ts, cmd(will, ts)+params(ts, append([]byte{termIs}, test.is...)...))
+
+		if want := cmd(do, ts) + params(ts, termSend); out != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, want)
+		}
+		if ts.Him != test.want {
+			t.Errorf("%s: speed %v, want %v", test.name, ts.Him, test.want)
+		}
+	}
+}
+
+func TestTermSpeedSend(t *testing.T) {
+	tests := []struct {
+		name string
+		us   Speed
+		want string
+	}{
+		{"speed", Speed{38400, 19200}, cmd(will, &TermSpeed{}) + params(&TermSpeed{}, termIs, '3', '8', '4', '0', '0', ',', '1', '9', '2', '0', '0')},
+		{"transmit only", Speed{9600, 0}, cmd(will, &TermSpeed{}) + params(&TermSpeed{}, termIs, '9', '6', '0', '0', ',', '0')},
+		{"none", Speed{}, cmd(wont, &TermSpeed{})},
+	}
+
+	for _, test := range tests {
+		ts := &TermSpeed{Us: test.us}
+		if out := run(t, ts, cmd(do, ts)+params(ts, termSend)); out != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out, test.want)
+		}
+	}
+}
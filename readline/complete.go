@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Completer completes the word being edited.
+type Completer interface {
+	// Complete returns the candidates for the word that begins at start and
+	// ends at the cursor position pos.  Positions are in runes.
+	Complete(line string, pos int) (start int, candidates []string)
+}
+
+// CompleterFunc is an adapter to allow the use of ordinary functions as
+// completers.
+type CompleterFunc func(line string, pos int) (start int, candidates []string)
+
+// Complete calls f(line, pos).
+func (f CompleterFunc) Complete(line string, pos int) (int, []string) { return f(line, pos) }
+
+// complete completes the word being edited.  A single candidate replaces
+// the word, followed by a space, and a common prefix of multiple
+// candidates extends it.  Otherwise the candidates are listed.
+func (e *Editor) complete() {
+	start, cands := e.candidates()
+	switch len(cands) {
+	case 0:
+		e.tn.Write([]byte("\a"))
+	case 1:
+		// Follow the word with a space unless one is already there.
+		s := cands[0]
+		if e.pos == len(e.line) || !unicode.IsSpace(e.line[e.pos]) {
+			s += " "
+		}
+		e.replace(start, s)
+	default:
+		if prefix := commonPrefix(cands); utf8.RuneCountInString(prefix) > e.pos-start {
+			e.replace(start, prefix)
+		} else {
+			e.list(cands)
+		}
+	}
+}
+
+// help lists the candidates for the word being edited.
+func (e *Editor) help() {
+	if _, cands := e.candidates(); len(cands) > 0 {
+		e.list(cands)
+	} else {
+		e.tn.Write([]byte("\a"))
+	}
+}
+
+// candidates returns the completion candidates for the word being edited.
+func (e *Editor) candidates() (int, []string) {
+	if e.Completer == nil {
+		return e.pos, nil
+	}
+
+	start, cands := e.Completer.Complete(string(e.line), e.pos)
+	return min(max(start, 0), e.pos), cands
+}
+
+// replace replaces the runes from start to the cursor with s.
+func (e *Editor) replace(start int, s string) {
+	r := []rune(s)
+	e.line = append(e.line[:start], append(r, e.line[e.pos:]...)...)
+	e.pos = start + len(r)
+	e.refresh()
+}
+
+// list displays the candidates in columns that fit the terminal width and
+// then redraws the prompt and line.
+func (e *Editor) list(cands []string) {
+	colWidth := 0
+	for _, c := range cands {
+		colWidth = max(colWidth, utf8.RuneCountInString(c)+2)
+	}
+	cols := max(e.width()/colWidth, 1)
+	rows := (len(cands) + cols - 1) / cols
+
+	e.moveEnd()
+	var b strings.Builder
+	b.WriteString("\r\n")
+	for row := range rows {
+		for col := range cols {
+			i := col*rows + row
+			if i >= len(cands) {
+				break
+			}
+			b.WriteString(cands[i])
+			if col < cols-1 && i+rows < len(cands) {
+				b.WriteString(strings.Repeat(" ", colWidth-utf8.RuneCountInString(cands[i])))
+			}
+		}
+		b.WriteString("\r\n")
+	}
+	e.tn.Write([]byte(b.String()))
+
+	e.row = 0
+	e.refresh()
+}
+
+// commonPrefix returns the longest common prefix of the strings.
+func commonPrefix(s []string) string {
+	prefix := []rune(s[0])
+	for _, c := range s[1:] {
+		r := []rune(c)
+		n := 0
+		for n < len(prefix) && n < len(r) && prefix[n] == r[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+
+	return string(prefix)
+}
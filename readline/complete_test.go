@@ -0,0 +1,169 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// words completes the word before the cursor from a list of words.
+func words(list ...string) Completer {
+	return CompleterFunc(func(line string, pos int) (int, []string) {
+		r := []rune(line)
+		start := pos
+		for start > 0 && r[start-1] != ' ' {
+			start--
+		}
+
+		var cands []string
+		for _, w := range list {
+			if strings.HasPrefix(w, string(r[start:pos])) {
+				cands = append(cands, w)
+			}
+		}
+
+		return start, cands
+	})
+}
+
+func TestComplete(t *testing.T) {
+	c := words("exit", "set", "shelf", "shell", "show")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"single", "e\t\r", "exit "},
+		{"single second word", "show e\t\r", "show exit "},
+		{"single before space", "e bar\x01\x06\t\r", "exit bar"},
+		{"single before word", "ebar\x01\x06\t\r", "exit bar"},
+		{"prefix", "she\t\r", "shel"},
+		{"prefix then single", "she\tf\t\r", "shelf "},
+		{"list", "sh\t\r", "sh"},
+		{"none", "x\t\r", "x"},
+		{"empty", "\t\r", ""},
+	}
+
+	for _, test := range tests {
+		e, _ := newEditor(test.in, DefaultWidth)
+		e.Completer = c
+
+		s, err := e.ReadLine("> ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.want {
+			t.Errorf("%s: read %q, want %q", test.name, s, test.want)
+		}
+	}
+}
+
+func TestCompleteOutput(t *testing.T) {
+	c := words("exit", "set", "show")
+
+	tests := []struct {
+		name      string
+		completer Completer
+		in        string
+		want      string
+	}{
+		{"none", c, "x\t", "> x\a"},
+		{"single", c, "e\t", "> e\r\x1b[J> exit \r\x1b[7C"},
+		{"list", c, "s\t", "> s\r\nset   show\r\n\r\x1b[J> s\r\x1b[3C"},
+		{"disabled", nil, "\t", "> \a"},
+	}
+
+	for _, test := range tests {
+		e, out := newEditor(test.in, DefaultWidth)
+		e.Completer = test.completer
+
+		if _, err := e.ReadLine("> "); err != io.EOF {
+			t.Fatalf("%s: error %v, want %v", test.name, err, io.EOF)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out.String(), test.want)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	tests := []struct {
+		name      string
+		completer Completer
+		in        string
+		line      string
+		want      string
+	}{
+		{"list", words("set", "show"), "s?\r", "s",
+			"> s\r\nset   show\r\n\r\x1b[J> s\r\x1b[3C\r\n"},
+		{"none", words("set", "show"), "x?\r", "x", "> x\a\r\n"},
+		{"disabled", nil, "s?\r", "s?", "> s?\r\n"},
+	}
+
+	for _, test := range tests {
+		e, out := newEditor(test.in, DefaultWidth)
+		e.Completer = test.completer
+		e.HelpKey = '?'
+
+		s, err := e.ReadLine("> ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.line {
+			t.Errorf("%s: read %q, want %q", test.name, s, test.line)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out.String(), test.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	cands := []string{"a", "bb", "ccc", "dddd", "e"}
+
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{"columns", 20, "a     ccc   e\r\nbb    dddd\r\n"},
+		{"one row", 80, "a     bb    ccc   dddd  e\r\n"},
+		{"narrow", 4, "a\r\nbb\r\nccc\r\ndddd\r\ne\r\n"},
+	}
+
+	for _, test := range tests {
+		e, out := newEditor("", test.width)
+		e.prompt, e.line, e.pos = "> ", []rune("x"), 0
+
+		e.list(cands)
+		want := "\r\x1b[J> x\r\x1b[3C" + "\r\n" + test.want + "\r\x1b[J> x\r\x1b[3C"
+		if out.String() != want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out.String(), want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		s    []string
+		want string
+	}{
+		{[]string{"abc"}, "abc"},
+		{[]string{"abc", "abd"}, "ab"},
+		{[]string{"abc", "ab", "abd"}, "ab"},
+		{[]string{"abc", "xyz"}, ""},
+		{[]string{"", "abc"}, ""},
+		{[]string{"héllo", "hélp"}, "hél"},
+	}
+
+	for _, test := range tests {
+		if p := commonPrefix(test.s); p != test.want {
+			t.Errorf("%q: prefix %q, want %q", test.s, p, test.want)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+// Package readline implements an interactive line editor for telnet
+// sessions in character mode, which is typically negotiated by enabling
+// the Echo and Suppress Go Ahead options for us.
+package readline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ebarkie/telnet"
+	"github.com/ebarkie/telnet/option"
+)
+
+// Errors.
+var (
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// DefaultWidth is the terminal width used when it's unknown.
+const DefaultWidth = 80
+
+// DefaultMaxHistory is the default maximum number of history lines.
+const DefaultMaxHistory = 100
+
+// Keys.
+const (
+	keyCtrlA = 0x01
+	keyCtrlB = 0x02
+	keyCtrlC = 0x03
+	keyCtrlD = 0x04
+	keyCtrlE = 0x05
+	keyCtrlF = 0x06
+	keyCtrlH = 0x08
+	keyTab   = 0x09
+	keyCtrlK = 0x0b
+	keyCtrlL = 0x0c
+	keyCtrlN = 0x0e
+	keyCtrlP = 0x10
+	keyCtrlU = 0x15
+	keyCtrlW = 0x17
+	keyESC   = 0x1b
+	keyDEL   = 0x7f
+)
+
+// Escape sequence keys, which are mapped outside of the Unicode range.
+const (
+	keyUp rune = utf8.MaxRune + 1 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyUnknown
+)
+
+// Editor is a line editor for a telnet context.
+type Editor struct {
+	tn *telnet.Ctx
+
+	// Width returns the terminal width in columns.  If it's nil then the
+	// width he reported using option.NAWS is used, if available.  Otherwise
+	// DefaultWidth is used.
+	Width func() int
+
+	// History holds previously entered lines, oldest first.
+	History []string
+	// MaxHistory is the maximum number of history lines kept.  If zero
+	// then DefaultMaxHistory is used.
+	MaxHistory int
+
+	// Completer is called to complete the word being edited when Tab is
+	// entered.  If it's nil then completion is disabled.
+	Completer Completer
+	// HelpKey lists the completion candidates for the word being edited
+	// when entered, such as '?' for router-style help.  The key can't be
+	// inserted.  If zero then it's disabled.
+	HelpKey rune
+
+	// in holds input that has been read but not processed.
+	in []byte
+	// cr indicates the last line ended with a carriage return so a
+	// following line feed or null is ignored.
+	cr bool
+
+	// prompt, line, and pos are the state of the line being edited.
+	prompt string
+	line   []rune
+	pos    int
+	// row is the row of the cursor relative to the prompt.
+	row int
+}
+
+// New allocates a new line editor for a telnet context.
+func New(tn *telnet.Ctx) *Editor {
+	return &Editor{tn: tn}
+}
+
+// ReadLine displays the prompt and reads a line of input, which is added
+// to the history if it's not empty.  The line ending is not included.
+//
+// ErrInterrupted is returned if ^C is entered and io.EOF if ^D is
+// entered on an empty line.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	e.prompt, e.line, e.pos, e.row = prompt, nil, 0, 0
+	e.tn.Write([]byte(prompt))
+
+	hist := len(e.History) // History index being edited
+	var saved []rune       // Line being entered before browsing history
+
+	for {
+		r, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			e.moveEnd()
+			e.tn.Write([]byte("\r\n"))
+			s := string(e.line)
+			e.addHistory(s)
+			return s, nil
+		case keyCtrlC:
+			e.moveEnd()
+			e.tn.Write([]byte("^C\r\n"))
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				e.tn.Write([]byte("\r\n"))
+				return "", io.EOF
+			}
+			e.delete(e.pos, e.pos+1)
+		case keyDelete:
+			e.delete(e.pos, e.pos+1)
+		case keyCtrlH, keyDEL:
+			e.delete(e.pos-1, e.pos)
+		case keyCtrlA, keyHome:
+			e.move(0)
+		case keyCtrlE, keyEnd:
+			e.move(len(e.line))
+		case keyCtrlB, keyLeft:
+			e.move(e.pos - 1)
+		case keyCtrlF, keyRight:
+			e.move(e.pos + 1)
+		case keyWordLeft:
+			e.move(e.wordStart())
+		case keyWordRight:
+			e.move(e.wordEnd())
+		case keyCtrlK:
+			e.delete(e.pos, len(e.line))
+		case keyCtrlU:
+			e.delete(0, e.pos)
+		case keyCtrlW:
+			e.delete(e.wordStart(), e.pos)
+		case keyCtrlL:
+			e.tn.Write([]byte("\x1b[H\x1b[2J"))
+			e.row = 0
+			e.refresh()
+		case keyCtrlP, keyUp:
+			if hist > 0 {
+				if hist == len(e.History) {
+					saved = e.line
+				}
+				hist--
+				e.set([]rune(e.History[hist]))
+			}
+		case keyCtrlN, keyDown:
+			if hist < len(e.History) {
+				hist++
+				if hist == len(e.History) {
+					e.set(saved)
+				} else {
+					e.set([]rune(e.History[hist]))
+				}
+			}
+		case keyTab:
+			e.complete()
+		default:
+			if r == e.HelpKey && r != 0 && e.Completer != nil {
+				e.help()
+				continue
+			}
+			if unicode.IsControl(r) || r > utf8.MaxRune {
+				// Ignore unknown control characters and sequences
+				continue
+			}
+			e.insert(r)
+		}
+	}
+}
+
+// addHistory adds a line to the history unless it's empty or a repeat of
+// the last line.
+func (e *Editor) addHistory(s string) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	if n := len(e.History); n > 0 && e.History[n-1] == s {
+		return
+	}
+
+	e.History = append(e.History, s)
+
+	max := e.MaxHistory
+	if max == 0 {
+		max = DefaultMaxHistory
+	}
+	if n := len(e.History); n > max {
+		e.History = e.History[n-max:]
+	}
+}
+
+// readByte returns the next byte of input.
+func (e *Editor) readByte() (byte, error) {
+	for len(e.in) == 0 {
+		buf := make([]byte, 256)
+		n, err := e.tn.Read(buf)
+		e.in = append(e.in, buf[:n]...)
+		if n == 0 && err != nil {
+			return 0, err
+		}
+	}
+
+	b := e.in[0]
+	e.in = e.in[1:]
+
+	return b, nil
+}
+
+// readKey returns the next key of input.  UTF-8 sequences are decoded and
+// escape sequences are mapped to keys.
+func (e *Editor) readKey() (rune, error) {
+	b, err := e.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	// A line feed or null following a carriage return completes the line
+	// ending.
+	if e.cr {
+		e.cr = false
+		if b == '\n' || b == 0 {
+			return e.readKey()
+		}
+	}
+
+	switch {
+	case b == '\r':
+		e.cr = true
+		return '\r', nil
+	case b == keyESC:
+		return e.readEscape()
+	case b < utf8.RuneSelf:
+		return rune(b), nil
+	}
+
+	// Multibyte UTF-8
+	p := []byte{b}
+	for !utf8.FullRune(p) {
+		if b, err = e.readByte(); err != nil {
+			return 0, err
+		}
+		p = append(p, b)
+	}
+	r, _ := utf8.DecodeRune(p)
+
+	return r, nil
+}
+
+// readEscape reads the remainder of an escape sequence and maps it to a
+// key.
+func (e *Editor) readEscape() (rune, error) {
+	b, err := e.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch b {
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case '[', 'O':
+	default:
+		return keyUnknown, nil
+	}
+
+	// Control sequence parameters followed by a final byte.
+	var params []byte
+	for {
+		if b, err = e.readByte(); err != nil {
+			return 0, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			break
+		}
+		params = append(params, b)
+	}
+
+	switch b {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	case '~':
+		switch string(params) {
+		case "1", "7":
+			return keyHome, nil
+		case "4", "8":
+			return keyEnd, nil
+		case "3":
+			return keyDelete, nil
+		}
+	}
+
+	return keyUnknown, nil
+}
+
+// width returns the terminal width.
+func (e *Editor) width() int {
+	if e.Width != nil {
+		if w := e.Width(); w > 0 {
+			return w
+		}
+	} else if n, ok := e.tn.State(option.NAWS{}.Byte()).Opt.(*option.NAWS); ok && n.Him.Width > 0 {
+		return int(n.Him.Width)
+	}
+
+	return DefaultWidth
+}
+
+// wordStart returns the position of the start of the word before the
+// cursor.
+func (e *Editor) wordStart() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.line[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.line[i-1]) {
+		i--
+	}
+
+	return i
+}
+
+// wordEnd returns the position of the end of the word after the cursor.
+func (e *Editor) wordEnd() int {
+	i := e.pos
+	for i < len(e.line) && unicode.IsSpace(e.line[i]) {
+		i++
+	}
+	for i < len(e.line) && !unicode.IsSpace(e.line[i]) {
+		i++
+	}
+
+	return i
+}
+
+// insert inserts a rune at the cursor.
+func (e *Editor) insert(r rune) {
+	e.line = append(e.line[:e.pos], append([]rune{r}, e.line[e.pos:]...)...)
+	e.pos++
+
+	// Echo the rune if it was appended and doesn't reach the right margin,
+	// otherwise redraw.
+	if e.pos == len(e.line) && (utf8.RuneCountInString(e.prompt)+e.pos)%e.width() != 0 {
+		e.tn.Write([]byte(string(r)))
+		return
+	}
+	e.refresh()
+}
+
+// delete deletes the runes from start up to end.
+func (e *Editor) delete(start, end int) {
+	start, end = max(start, 0), min(end, len(e.line))
+	if start >= end {
+		return
+	}
+
+	e.line = append(e.line[:start], e.line[end:]...)
+	e.pos = start
+	e.refresh()
+}
+
+// move moves the cursor.
+func (e *Editor) move(pos int) {
+	pos = min(max(pos, 0), len(e.line))
+	if pos == e.pos {
+		return
+	}
+
+	e.pos = pos
+	e.refresh()
+}
+
+// moveEnd moves the cursor to the end of the line.
+func (e *Editor) moveEnd() {
+	e.move(len(e.line))
+}
+
+// set replaces the line and moves the cursor to the end.
+func (e *Editor) set(line []rune) {
+	e.line = append([]rune(nil), line...)
+	e.pos = len(e.line)
+	e.refresh()
+}
+
+// refresh redraws the prompt and line and positions the cursor, taking
+// wrapping at the terminal width into account.
+func (e *Editor) refresh() {
+	w := e.width()
+	var b strings.Builder
+
+	// Move to the start of the prompt and clear to the end of the screen.
+	if e.row > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", e.row)
+	}
+	b.WriteString("\r\x1b[J")
+
+	b.WriteString(e.prompt)
+	b.WriteString(string(e.line))
+
+	// If the end is at the right margin then force a wrap so the cursor
+	// position is known.
+	plen := utf8.RuneCountInString(e.prompt)
+	end := plen + len(e.line)
+	if end > 0 && end%w == 0 {
+		b.WriteString("\r\n")
+	}
+
+	// Move from the end to the cursor.
+	cur := plen + e.pos
+	if rows := end/w - cur/w; rows > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", rows)
+	}
+	b.WriteString("\r")
+	if col := cur % w; col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", col)
+	}
+	e.row = cur / w
+
+	e.tn.Write([]byte(b.String()))
+}
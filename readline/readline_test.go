@@ -0,0 +1,230 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/ebarkie/telnet"
+	"github.com/ebarkie/telnet/option"
+)
+
+// rwbuf is a ReadWriter that reads from a fixed input and records
+// writes.
+type rwbuf struct {
+	in  *bytes.Reader
+	out bytes.Buffer
+}
+
+func (rw *rwbuf) Read(b []byte) (int, error)  { return rw.in.Read(b) }
+func (rw *rwbuf) Write(b []byte) (int, error) { return rw.out.Write(b) }
+
+// newEditor returns an editor that reads from a fixed input and a buffer
+// that records its output.
+func newEditor(in string, width int) (*Editor, *bytes.Buffer) {
+	rw := &rwbuf{in: bytes.NewReader([]byte(in))}
+	e := New(telnet.NewReadWriter(rw))
+	e.Width = func() int { return width }
+
+	return e, &rw.out
+}
+
+// readLines reads lines until an error, which is returned along with the
+// lines.
+func readLines(e *Editor) ([]string, error) {
+	var lines []string
+	for {
+		s, err := e.ReadLine("> ")
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, s)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		name string
+		hist []string
+		in   string
+		want []string
+		err  error
+	}{
+		{"cr", nil, "abc\r", []string{"abc"}, io.EOF},
+		{"lf", nil, "abc\n", []string{"abc"}, io.EOF},
+		{"crlf", nil, "abc\r\ndef\r\n", []string{"abc", "def"}, io.EOF},
+		{"crnul", nil, "abc\r\x00def\r\x00", []string{"abc", "def"}, io.EOF},
+		{"empty", nil, "\r\r\n\n", []string{"", "", ""}, io.EOF},
+		{"utf8", nil, "h\xc3\xa9llo \xe2\x82\xac\r", []string{"héllo €"}, io.EOF},
+		{"control", nil, "a\x07\x1ab\r", []string{"ab"}, io.EOF},
+
+		{"left", nil, "ab\x1b[Dc\r", []string{"acb"}, io.EOF},
+		{"ctrl-b", nil, "ab\x02c\r", []string{"acb"}, io.EOF},
+		{"right", nil, "ab\x01\x1b[Cc\r", []string{"acb"}, io.EOF},
+		{"ctrl-f", nil, "ab\x01\x06c\r", []string{"acb"}, io.EOF},
+		{"home", nil, "ab\x1b[Hc\r", []string{"cab"}, io.EOF},
+		{"home ss3", nil, "ab\x1bOHc\r", []string{"cab"}, io.EOF},
+		{"home vt", nil, "ab\x1b[1~c\x1b[7~d\r", []string{"dcab"}, io.EOF},
+		{"end", nil, "ab\x01\x1b[Fc\r", []string{"abc"}, io.EOF},
+		{"end vt", nil, "ab\x01\x1b[4~c\x01\x1b[8~d\r", []string{"abcd"}, io.EOF},
+		{"ctrl-a ctrl-e", nil, "ab\x01c\x05d\r", []string{"cabd"}, io.EOF},
+		{"word left", nil, "foo bar\x1bbx\r", []string{"foo xbar"}, io.EOF},
+		{"word right", nil, "foo bar\x01\x1bfx\r", []string{"foox bar"}, io.EOF},
+		{"unknown csi", nil, "ab\x1b[5~\x1b[1;5Ac\r", []string{"abc"}, io.EOF},
+		{"unknown esc", nil, "a\x1bxb\r", []string{"ab"}, io.EOF},
+
+		{"backspace", nil, "abc\x7f\x08d\r", []string{"ad"}, io.EOF},
+		{"backspace start", nil, "ab\x01\x7f\r", []string{"ab"}, io.EOF},
+		{"delete", nil, "abc\x01\x1b[3~\r", []string{"bc"}, io.EOF},
+		{"ctrl-d", nil, "abc\x01\x04\r", []string{"bc"}, io.EOF},
+		{"ctrl-d end", nil, "abc\x04\r", []string{"abc"}, io.EOF},
+		{"kill end", nil, "foo bar\x1bb\x0b\r", []string{"foo "}, io.EOF},
+		{"kill start", nil, "foo bar\x1bb\x15\r", []string{"bar"}, io.EOF},
+		{"kill word", nil, "foo bar\x17\r", []string{"foo "}, io.EOF},
+		{"kill word spaces", nil, "foo bar  \x17\r", []string{"foo "}, io.EOF},
+		{"kill word middle", nil, "foo bar\x02\x17\r", []string{"foo r"}, io.EOF},
+
+		{"up", []string{"one", "two"}, "\x1b[A\r", []string{"two"}, io.EOF},
+		{"ctrl-p", []string{"one", "two"}, "\x10\x10\r", []string{"one"}, io.EOF},
+		{"up oldest", []string{"one", "two"}, "\x1b[A\x1b[A\x1b[A\r", []string{"one"}, io.EOF},
+		{"down", []string{"one", "two"}, "\x10\x10\x1b[B\r", []string{"two"}, io.EOF},
+		{"down saved", []string{"one", "two"}, "x\x10\x10\x0e\x0e\r", []string{"x"}, io.EOF},
+		{"down newest", []string{"one"}, "x\x1b[B\r", []string{"x"}, io.EOF},
+		{"edit history", []string{"one"}, "\x10s\r\x10\r", []string{"ones", "ones"}, io.EOF},
+
+		{"interrupt", nil, "abc\x03def\r", nil, ErrInterrupted},
+		{"eof", nil, "abc\r\x04", []string{"abc"}, io.EOF},
+	}
+
+	for _, test := range tests {
+		e, _ := newEditor(test.in, DefaultWidth)
+		e.History = slices.Clone(test.hist)
+
+		lines, err := readLines(e)
+		if !slices.Equal(lines, test.want) {
+			t.Errorf("%s: read %q, want %q", test.name, lines, test.want)
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		hist []string
+		in   string
+		want []string
+	}{
+		{"add", 0, []string{"one"}, "two\r", []string{"one", "two"}},
+		{"empty", 0, []string{"one"}, "\r  \r", []string{"one"}},
+		{"repeat", 0, []string{"one"}, "one\r\x10\r", []string{"one"}},
+		{"not repeat", 0, []string{"one", "two"}, "one\r", []string{"one", "two", "one"}},
+		{"max", 2, []string{"one", "two"}, "three\r", []string{"two", "three"}},
+	}
+
+	for _, test := range tests {
+		e, _ := newEditor(test.in, DefaultWidth)
+		e.History = slices.Clone(test.hist)
+		e.MaxHistory = test.max
+
+		readLines(e)
+		if !slices.Equal(e.History, test.want) {
+			t.Errorf("%s: history %q, want %q", test.name, e.History, test.want)
+		}
+	}
+
+	// The history is limited to DefaultMaxHistory.
+	var in bytes.Buffer
+	for i := range DefaultMaxHistory + 1 {
+		in.WriteString(strconv.Itoa(i) + "\r")
+	}
+	e, _ := newEditor(in.String(), DefaultWidth)
+	readLines(e)
+	if len(e.History) != DefaultMaxHistory || e.History[0] != "1" {
+		t.Errorf("default max history %d oldest %q, want %d %q", len(e.History), e.History[0], DefaultMaxHistory, "1")
+	}
+}
+
+func TestReadLineOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		in    string
+		want  string
+	}{
+		{"echo", 80, "ab\r", "> ab\r\n"},
+		{"left", 80, "ab\x1b[D\r",
+			"> ab" +
+				"\r\x1b[J> ab\r\x1b[3C" +
+				"\r\x1b[J> ab\r\x1b[4C\r\n"},
+		{"insert", 80, "ab\x01c\r",
+			"> ab" +
+				"\r\x1b[J> ab\r\x1b[2C" +
+				"\r\x1b[J> cab\r\x1b[3C" +
+				"\r\x1b[J> cab\r\x1b[5C\r\n"},
+		{"backspace", 80, "ab\x7f\r",
+			"> ab" +
+				"\r\x1b[J> a\r\x1b[3C\r\n"},
+		{"wrap", 5, "abcd\x01\r",
+			"> ab" +
+				"\r\x1b[J> abc\r\n\r" +
+				"d" +
+				"\x1b[1A\r\x1b[J> abcd\x1b[1A\r\x1b[2C" +
+				"\r\x1b[J> abcd\r\x1b[1C\r\n"},
+		{"wrap margin", 5, "abc\x02\r",
+			"> ab" +
+				"\r\x1b[J> abc\r\n\r" +
+				"\x1b[1A\r\x1b[J> abc\r\n\x1b[1A\r\x1b[4C" +
+				"\r\x1b[J> abc\r\n\r\r\n"},
+		{"clear", 80, "a\x0c\r",
+			"> a" +
+				"\x1b[H\x1b[2J\r\x1b[J> a\r\x1b[3C\r\n"},
+		{"interrupt", 80, "ab\x01\x03",
+			"> ab" +
+				"\r\x1b[J> ab\r\x1b[2C" +
+				"\r\x1b[J> ab\r\x1b[4C^C\r\n"},
+		{"eof", 80, "\x04", "> \r\n"},
+	}
+
+	for _, test := range tests {
+		e, out := newEditor(test.in, test.width)
+		e.ReadLine("> ")
+		if out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, out.String(), test.want)
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	naws := &option.NAWS{}
+	e := New(telnet.NewReadWriter(&rwbuf{in: bytes.NewReader(nil)}, naws))
+
+	tests := []struct {
+		name  string
+		width func() int
+		naws  uint16
+		want  int
+	}{
+		{"default", nil, 0, DefaultWidth},
+		{"naws", nil, 100, 100},
+		{"func", func() int { return 40 }, 100, 40},
+		{"func zero", func() int { return 0 }, 100, DefaultWidth},
+	}
+
+	for _, test := range tests {
+		e.Width, naws.Him.Width = test.width, test.naws
+		if w := e.width(); w != test.want {
+			t.Errorf("%s: width %d, want %d", test.name, w, test.want)
+		}
+	}
+}
@@ -4,16 +4,14 @@
 
 package telnet
 
-import (
-	"bytes"
-	"log/slog"
-)
+import "bytes"
 
 // readState is the state of the Reader.
 type readState uint
 
 const (
 	rsData   readState = iota // Reading data
+	rsCR                      // Carriage return received in NVT mode
 	rsIAC                     // Interpret as Command received
 	rsInd                     // Will, won't, do, or don't indicator received
 	rsSub                     // Subnegotiation
@@ -27,61 +25,89 @@ const (
 // data will be passed as-is.
 func (t *Ctx) Read(b []byte) (n int, err error) {
 	if len(b) > 0 {
-		// Drain pending data from a previous empty-buffer Read first.
-		if len(t.pending) > 0 {
-			n = copy(b, t.pending)
-			t.pending = t.pending[n:]
-			return
-		}
-
 		// The user is expecting at least one byte to be returned so keep
-		// reading until we get some data.
-		for err == nil && n < 1 {
-			n, err = t.read(b)
+		// reading until we get some data.  Data left pending from a
+		// previous empty-buffer Read is drained first.
+		for err == nil && t.buffered() < 1 {
+			err = t.read(len(b))
 		}
 	} else {
-		buf := make([]byte, 16)
-		n, err = t.read(buf)
-		if n > 0 {
-			t.pending = append(t.pending, buf[:n]...)
-			n = 0
-		}
+		err = t.read(16)
 	}
 
+	t.mu.Lock()
+	n = copy(b, t.pending)
+	t.pending = t.pending[n:]
+	t.mu.Unlock()
+
 	return
 }
 
-func (t *Ctx) read(b []byte) (n int, err error) {
-	buf := make([]byte, len(b))
+// buffered returns the number of parsed data bytes that have not been
+// returned to the user yet.
+func (t *Ctx) buffered() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.pending)
+}
+
+func (t *Ctx) read(size int) (err error) {
+	buf := make([]byte, size)
 	var num int
 	num, err = t.rw.Read(buf)
+	urgent := t.urgent()
 
 	t.mu.Lock()
-	for i := range num {
+	if urgent {
+		// The peer sent a Synch so discard data, but continue to process
+		// commands, until the Data Mark is reached.
+		t.discard = true
+	}
+	t.reading = true
+	for i := 0; i < num; i++ {
 		switch t.rs {
 		case rsData:
 			// In data mode if an Interpret as Command is not received then
-			// pass through the data to the read buffer.
-			switch buf[i] {
-			case byte(iac):
+			// pass through the data to the pending buffer.
+			switch {
+			case buf[i] == byte(iac):
 				// Begin IAC
 				t.rs = rsIAC
+			case buf[i] == '\r' && t.nvtHim():
+				// Begin end of line
+				t.rs = rsCR
 			default:
 				// Data
-				b[n] = buf[i]
-				n++
+				t.data(buf[i])
+			}
+		case rsCR:
+			// A carriage return was received in NVT mode so translate a
+			// following line feed or null.
+			switch buf[i] {
+			case '\n':
+				t.data('\n')
+				t.rs = rsData
+			case 0:
+				t.data('\r')
+				t.rs = rsData
+			case '\r':
+				t.data('\r')
+			case byte(iac):
+				t.data('\r')
+				t.rs = rsIAC
+			default:
+				t.data('\r')
+				t.data(buf[i])
+				t.rs = rsData
 			}
 		case rsIAC:
 			// An Interpret as Command was received.
 			cmd := Command(buf[i])
 			switch cmd {
-			case AYT:
-				t.rw.Write([]byte("I am here"))
-				t.rs = rsData
 			case iac:
 				// Escaped IAC
-				b[n] = buf[i]
-				n++
+				t.data(buf[i])
 
 				t.rs = rsData
 			case will, wont, do, dont:
@@ -90,16 +116,14 @@ func (t *Ctx) read(b []byte) (n int, err error) {
 			case sb:
 				t.cb = []byte{}
 				t.rs = rsSub
-			case NOP:
-				// No operation
 			default:
-				slog.Debug("ignoring unhandled command", "cmd", cmd)
+				t.command(cmd)
 				t.rs = rsData
 			}
 		case rsInd:
 			// A will, won't, do, or don't indicated was previously given so
 			// negotiate the option.
-			t.negotiate(Command(t.cb[0]), buf[i])
+			t.report(t.negotiate(Command(t.cb[0]), buf[i]))
 
 			t.rs = rsData
 		case rsSub:
@@ -109,7 +133,7 @@ func (t *Ctx) read(b []byte) (n int, err error) {
 			case byte(iac):
 				t.rs = rsSubIAC
 			default:
-				t.cb = append(t.cb, buf[i])
+				t.report(t.param(buf[i]))
 			}
 		case rsSubIAC:
 			// An Interpret as Command received during subnegotiation is only
@@ -118,31 +142,66 @@ func (t *Ctx) read(b []byte) (n int, err error) {
 			switch Command(buf[i]) {
 			case iac:
 				// Escaped IAC
-				t.cb = append(t.cb, buf[i])
+				t.report(t.param(buf[i]))
+
+				t.rs = rsSub
 			case se:
-				t.subnegotiate(t.cb[0], t.cb[1:])
+				if len(t.cb) > 0 && !t.overflow {
+					t.subnegotiate(t.cb[0], t.cb[1:])
+				}
+				t.overflow = false
 
 				t.rs = rsData
 			default:
-				slog.Error("unexpected byte in subnegotiation", "byte", buf[i])
+				if len(t.cb) > 0 {
+					t.report(&ParamsError{Opt: t.os.load(t.cb[0]).opt, Byte: buf[i]})
+				}
+				t.overflow = false
+
+				// Abort the subnegotiation and process the byte as a
+				// command.
 				t.rs = rsIAC
+				i--
 			}
 		}
+
+		if t.upgrade != nil {
+			// The remaining bytes belong to the new connection.
+			t.swap(buf[i+1 : num])
+			break
+		}
+	}
+	if err != nil && t.rs == rsCR {
+		// The end of line can't be completed so pass through the carriage
+		// return.
+		t.data('\r')
+		t.rs = rsData
+	}
+	t.reading = false
+	if err == nil {
+		err, t.ferr = t.ferr, nil
 	}
 	t.mu.Unlock()
 
 	return
 }
 
+// data appends a data byte to the pending buffer unless data is being
+// discarded.
+func (t *Ctx) data(b byte) {
+	if !t.discard {
+		t.pending = append(t.pending, b)
+	}
+}
+
 // Write is a telnet Writer.
 //
-// Any Interpret as Command bytes are escaped and the result is written
-// using the Writer provided by the client.
+// Any Interpret as Command bytes are escaped, end of lines are translated
+// if NVT mode is enabled, and the result is written using the Writer
+// provided by the client.
 func (t *Ctx) Write(b []byte) (int, error) {
-	buf := bytes.ReplaceAll(b, []byte{byte(iac)}, []byte{byte(iac), byte(iac)})
-
 	t.mu.Lock()
-	_, err := t.rw.Write(buf)
+	err := t.write(b)
 	t.mu.Unlock()
 
 	if err != nil {
@@ -150,3 +209,20 @@ func (t *Ctx) Write(b []byte) (int, error) {
 	}
 	return len(b), nil
 }
+
+// write escapes any Interpret as Command bytes, translates end of lines
+// if NVT mode is enabled, and writes the result.
+func (t *Ctx) write(b []byte) error {
+	switch {
+	case t.nvtUs():
+		b = t.nvtEncode(b)
+	case t.wcr:
+		// Complete a carriage return held while translation was enabled.
+		b = append([]byte{0}, b...)
+		t.wcr = false
+	}
+	buf := bytes.ReplaceAll(b, []byte{byte(iac)}, []byte{byte(iac), byte(iac)})
+	_, err := t.rw.Write(buf)
+
+	return err
+}
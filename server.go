@@ -0,0 +1,340 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// Errors.
+var (
+	// ErrServerClosed is returned by the Server Serve and ListenAndServe
+	// methods after a call to Shutdown or Close.
+	ErrServerClosed = errors.New("server closed")
+	// ErrNoHandler is returned by the Server Serve and ListenAndServe
+	// methods when Handler is nil.
+	ErrNoHandler = errors.New("no handler")
+)
+
+// DefaultNegotiateTimeout is the default time a Server waits for the
+// initial negotiation of a session to settle.
+const DefaultNegotiateTimeout = 5 * time.Second
+
+// Handler responds to a telnet session.  The session is closed when
+// ServeTelnet returns.
+type Handler interface {
+	ServeTelnet(s *Session)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as
+// handlers.
+type HandlerFunc func(s *Session)
+
+// ServeTelnet calls f(s).
+func (f HandlerFunc) ServeTelnet(s *Session) { f(s) }
+
+// Session is a telnet session accepted by a Server.
+type Session struct {
+	*Ctx
+
+	// Conn is the accepted connection.  It's not updated when the session
+	// is upgraded, such as by START_TLS, so data must be read and written
+	// using the session itself.
+	Conn net.Conn
+	// Results are the results of the initial negotiation.
+	Results []Result
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// done indicates the handler returned and bye is closed once the
+	// goodbye message is written.  Both are guarded by the server mutex.
+	done bool
+	bye  chan struct{}
+}
+
+// Context returns the context of the session, which is canceled when the
+// server is shutting down or the handler returns.
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Server is a telnet server.
+type Server struct {
+	// Addr is the TCP address to listen on.  If empty then ":23" is used.
+	Addr string
+	// Handler is called for each session.  It must not be nil.
+	Handler Handler
+
+	// Options returns the options available for negotiation for a new
+	// session.  It's called for each session since options hold state.
+	Options func() []Option
+	// Negotiate is the initial negotiation for a new session.  The options
+	// are matched by byte code to those returned by Options.
+	Negotiate []Ask
+	// NegotiateTimeout is the maximum time to wait for the TLS handshake, if
+	// any, and the initial negotiation to settle.  If zero then
+	// DefaultNegotiateTimeout is used.
+	NegotiateTimeout time.Duration
+
+	// Goodbye is written to each session when the server is shutting down.
+	Goodbye []byte
+
+	// TLSConfig is the TLS configuration used by ServeTLS and
+	// ListenAndServeTLS.  It may be nil.
+	TLSConfig *tls.Config
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	sessions  map[*Session]struct{}
+}
+
+// ListenAndServe listens on the TCP network address Addr and then calls
+// Serve.
+func (srv *Server) ListenAndServe() error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":23"
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return srv.Serve(l)
+}
+
+// Serve accepts connections on the listener and calls the handler for
+// each session in a new goroutine.  It always returns a non-nil error and
+// closes the listener.
+func (srv *Server) Serve(l net.Listener) error {
+	if srv.Handler == nil {
+		l.Close()
+		return ErrNoHandler
+	}
+	if !srv.trackListener(l, true) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer srv.trackListener(l, false)
+	defer l.Close()
+
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if srv.shuttingDown() {
+				return ErrServerClosed
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+
+			// Back off on errors such as running out of file descriptors.
+			delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+			slog.Error("accept error", "err", err, "retry", delay)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		go srv.serve(conn)
+	}
+}
+
+// serve runs a session for a connection.
+func (srv *Server) serve(conn net.Conn) {
+	var opts []Option
+	if srv.Options != nil {
+		opts = srv.Options()
+	}
+
+	s := &Session{Ctx: NewReadWriter(conn, opts...), Conn: conn}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	defer s.cancel()
+	if !srv.trackSession(s, true) {
+		conn.Close()
+		return
+	}
+	defer srv.trackSession(s, false)
+	defer s.Close()
+	defer srv.waitGoodbye(s)
+
+	timeout := srv.NegotiateTimeout
+	if timeout == 0 {
+		timeout = DefaultNegotiateTimeout
+	}
+
+	if tc, ok := conn.(*tls.Conn); ok {
+		ctx, cancel := context.WithTimeout(s.ctx, timeout)
+		err := tc.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			slog.Debug("tls handshake error", "addr", conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+
+	if len(srv.Negotiate) > 0 {
+		ctx, cancel := context.WithTimeout(s.ctx, timeout)
+		s.Results, _ = s.Negotiate(ctx, srv.Negotiate...)
+		cancel()
+	}
+
+	srv.Handler.ServeTelnet(s)
+}
+
+// Shutdown gracefully shuts down the server.  Listeners are closed, the
+// context of each session is canceled, any blocked reads are interrupted,
+// and the goodbye message is written.  It then waits for the handlers to
+// return.  If the context is done first then the remaining sessions are
+// closed and its error is returned.
+//
+// The goodbye message is written without waiting for it, using the context
+// deadline, if any, as the write deadline.  A session whose handler returns
+// is closed once its goodbye message is written.
+func (srv *Server) Shutdown(ctx context.Context) error {
+	srv.mu.Lock()
+	srv.closeLocked()
+	sessions := make(map[*Session]chan struct{})
+	for s := range srv.sessions {
+		sessions[s] = nil
+		if !s.done && s.bye == nil && len(srv.Goodbye) > 0 {
+			s.bye = make(chan struct{})
+			sessions[s] = s.bye
+		}
+	}
+	srv.mu.Unlock()
+
+	for s, bye := range sessions {
+		s.cancel()
+		s.Conn.SetReadDeadline(time.Now())
+		if bye == nil {
+			continue
+		}
+
+		if d, ok := ctx.Deadline(); ok {
+			s.Conn.SetWriteDeadline(d)
+		}
+		// The handler may be blocked writing so don't wait for the goodbye.
+		go func() {
+			s.Write(srv.Goodbye)
+			close(bye)
+		}()
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		srv.mu.Lock()
+		idle := len(srv.sessions) == 0
+		srv.mu.Unlock()
+		if idle {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			srv.Close()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close immediately closes all listeners and sessions.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.closeLocked()
+	for s := range srv.sessions {
+		s.cancel()
+		s.Conn.Close()
+	}
+
+	return nil
+}
+
+// waitGoodbye waits for the goodbye message to be written to a session
+// whose handler returned.
+func (srv *Server) waitGoodbye(s *Session) {
+	srv.mu.Lock()
+	s.done = true
+	bye := s.bye
+	srv.mu.Unlock()
+
+	if bye != nil {
+		<-bye
+	}
+}
+
+// closeLocked marks the server closed and closes all listeners.
+func (srv *Server) closeLocked() {
+	srv.closed = true
+	for l := range srv.listeners {
+		l.Close()
+	}
+}
+
+// shuttingDown indicates if the server is shutting down.
+func (srv *Server) shuttingDown() bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return srv.closed
+}
+
+// trackListener adds or removes a listener.  It returns false if a
+// listener can't be added because the server is closed.
+func (srv *Server) trackListener(l net.Listener, add bool) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if !add {
+		delete(srv.listeners, l)
+		return true
+	}
+
+	if srv.closed {
+		return false
+	}
+	if srv.listeners == nil {
+		srv.listeners = make(map[net.Listener]struct{})
+	}
+	srv.listeners[l] = struct{}{}
+
+	return true
+}
+
+// trackSession adds or removes a session.  It returns false if a session
+// can't be added because the server is closed.
+func (srv *Server) trackSession(s *Session, add bool) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if !add {
+		delete(srv.sessions, s)
+		return true
+	}
+
+	if srv.closed {
+		return false
+	}
+	if srv.sessions == nil {
+		srv.sessions = make(map[*Session]struct{})
+	}
+	srv.sessions[s] = struct{}{}
+
+	return true
+}
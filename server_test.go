@@ -0,0 +1,152 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// startServer starts serving on a local listener and returns its address.
+func startServer(t *testing.T, srv *Server) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+
+	return l.Addr().String()
+}
+
+func TestServerShutdown(t *testing.T) {
+	srv := &Server{
+		Handler: HandlerFunc(func(s *Session) {
+			s.Write([]byte("hello\n"))
+			io.Copy(io.Discard, s)
+		}),
+		Goodbye: []byte("goodbye\n"),
+	}
+	addr := startServer(t, srv)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	b := make([]byte, 6)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown error %v", err)
+	}
+
+	b, _ = io.ReadAll(conn)
+	if string(b) != "goodbye\n" {
+		t.Errorf("read %q, want %q", b, "goodbye\n")
+	}
+}
+
+func TestServerShutdownBlocked(t *testing.T) {
+	// The session writes to a client that isn't reading so it blocks, along
+	// with the goodbye message.
+	canceled := make(chan struct{})
+	srv := &Server{
+		Handler: HandlerFunc(func(s *Session) {
+			go func() {
+				b := make([]byte, 64*1024)
+				for {
+					if _, err := s.Write(b); err != nil {
+						return
+					}
+				}
+			}()
+			<-s.Context().Done()
+			close(canceled)
+		}),
+		Goodbye: []byte("goodbye\n"),
+	}
+	addr := startServer(t, srv)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan error)
+	go func() { done <- srv.Shutdown(context.Background()) }()
+
+	// The session is canceled even though the context has no deadline.
+	select {
+	case <-canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("session was not canceled")
+	}
+
+	// Shutdown completes once the client reads.
+	go io.Copy(io.Discard, conn)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Shutdown error %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return")
+	}
+}
+
+func TestServerShutdownDeadline(t *testing.T) {
+	// The handler ignores the cancellation so Shutdown gives up at the
+	// deadline.
+	release := make(chan struct{})
+	defer close(release)
+	srv := &Server{
+		Handler: HandlerFunc(func(s *Session) {
+			<-release
+		}),
+		Goodbye: []byte("goodbye\n"),
+	}
+	addr := startServer(t, srv)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	b, _ := io.ReadAll(conn)
+	if string(b) != "goodbye\n" {
+		t.Errorf("read %q, want %q", b, "goodbye\n")
+	}
+}
+
+func TestServerNoHandler(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&Server{}).Serve(l); !errors.Is(err, ErrNoHandler) {
+		t.Errorf("Serve error %v, want %v", err, ErrNoHandler)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Side is a side of an option negotiation.
+type Side byte
+
+// Option negotiation sides.
+const (
+	Him Side = iota // He performs the option
+	Us              // We perform the option
+)
+
+func (s Side) String() string {
+	if s == Us {
+		return "us"
+	}
+	return "him"
+}
+
+// OptionState is the negotiation state of an option.
+type OptionState struct {
+	Opt     Option
+	Him, Us NegState
+}
+
+func (s OptionState) String() string {
+	return fmt.Sprintf("%s him=%s us=%s", s.Opt, s.Him, s.Us)
+}
+
+// Side returns the negotiation state for a side.
+func (s OptionState) Side(side Side) NegState {
+	if side == Us {
+		return s.Us
+	}
+	return s.Him
+}
+
+// Enabled indicates if an option is enabled for a side.
+func (t *Ctx) Enabled(opt Option, side Side) bool {
+	return t.State(opt.Byte()).Side(side) == NegYes
+}
+
+// State returns the negotiation state of an option code.
+func (t *Ctx) State(code byte) OptionState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.os.load(code)
+	return OptionState{Opt: s.opt, Him: s.him, Us: s.us}
+}
+
+// States returns the negotiation state of every available option, ordered
+// by code.
+func (t *Ctx) States() []OptionState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var states []OptionState
+	for _, code := range slices.Sorted(maps.Keys(t.os)) {
+		s := t.os[code]
+		states = append(states, OptionState{Opt: s.opt, Him: s.him, Us: s.us})
+	}
+
+	return states
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"log/slog"
+	"net"
+)
+
+// SendSynch sends a Synch, which is a Data Mark sent as TCP urgent
+// data.  The client discards any data it has not yet processed up to the
+// Data Mark, so this is typically sent after receiving an Interrupt
+// Process or Abort Output command.
+//
+// If the underlying ReadWriter is not a *net.TCPConn then the Data Mark
+// is sent in-band.
+func (t *Ctx) SendSynch() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.emit(Event{Kind: EventCmdSent, Cmd: DM})
+	b := []byte{byte(iac), byte(DM)}
+	if conn, ok := t.rw.(*net.TCPConn); ok {
+		return sendUrgent(conn, b)
+	}
+
+	_, err := t.rw.Write(b)
+	return err
+}
+
+// urgent indicates if the peer has sent a Synch that has not been
+// completely read yet.  Only a *net.TCPConn can carry urgent data.
+func (t *Ctx) urgent() bool {
+	if conn, ok := t.rw.(*net.TCPConn); ok {
+		return atMark(conn)
+	}
+
+	return false
+}
+
+// synch discards any data that has not been returned by Read yet and
+// leaves urgent mode.  It's called when a Data Mark is received since the
+// data preceding it is meant to be flushed.  A Data Mark received without
+// urgent data, such as one sent in-band, is a no operation.
+func (t *Ctx) synch() {
+	if !t.discard {
+		return
+	}
+
+	slog.Debug("synch received", "discarded", len(t.pending))
+	t.pending = t.pending[:0]
+	t.discard = false
+}
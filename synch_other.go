@@ -0,0 +1,24 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package telnet
+
+import "net"
+
+// sendUrgent sends b in-band since urgent data is not supported on this
+// platform.
+func sendUrgent(conn *net.TCPConn, b []byte) error {
+	_, err := conn.Write(b)
+	return err
+}
+
+// setOOBInline is a no-op since urgent data is not supported on this
+// platform.
+func setOOBInline(conn *net.TCPConn) error { return nil }
+
+// atMark always returns false since urgent data is not supported on this
+// platform.
+func atMark(conn *net.TCPConn) bool { return false }
@@ -0,0 +1,31 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDataMarkInBand(t *testing.T) {
+	for _, handled := range []bool{false, true} {
+		tn := NewReadWriter(newRWBuf("abcdef\xff\xf2gh"))
+		var dm int
+		if handled {
+			tn.HandleCmd(DM, func(*Ctx, Command) { dm++ })
+		}
+
+		b, err := io.ReadAll(tn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "abcdefgh" {
+			t.Errorf("handled %v: read %q, want %q", handled, b, "abcdefgh")
+		}
+		if handled && dm != 1 {
+			t.Errorf("DM handler called %d times, want 1", dm)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package telnet
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// sendUrgent sends b as TCP urgent data so the urgent pointer marks the
+// last byte.
+func sendUrgent(conn *net.TCPConn, b []byte) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var serr error
+	err = rc.Write(func(fd uintptr) bool {
+		serr = syscall.Sendto(int(fd), b, syscall.MSG_OOB, nil)
+		return serr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+
+	return serr
+}
+
+// setOOBInline configures the connection to receive urgent data in-band
+// so a Data Mark sent as part of a Synch is not lost.
+func setOOBInline(conn *net.TCPConn) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var serr error
+	err = rc.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_OOBINLINE, 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	return serr
+}
+
+// atMark indicates if the next byte to be read is TCP urgent data.
+func atMark(conn *net.TCPConn) bool {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var mark int32
+	var errno syscall.Errno
+	rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.SIOCATMARK, uintptr(unsafe.Pointer(&mark)))
+	})
+
+	return errno == 0 && mark != 0
+}
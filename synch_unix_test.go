@@ -0,0 +1,62 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package telnet
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSynch(t *testing.T) {
+	for _, handled := range []bool{false, true} {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+
+		go func() {
+			conn, err := net.Dial("tcp", l.Addr().String())
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			tn := NewReadWriter(conn)
+			tn.Write([]byte("discard"))
+			time.Sleep(50 * time.Millisecond)
+			tn.SendSynch()
+			tn.Write([]byte("keep"))
+		}()
+
+		conn, err := l.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tn := NewReadWriter(conn)
+		var dm int
+		if handled {
+			tn.HandleCmd(DM, func(*Ctx, Command) { dm++ })
+		}
+
+		// Wait for everything to arrive so the Synch overtakes the data.
+		time.Sleep(150 * time.Millisecond)
+		b, err := io.ReadAll(tn)
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "keep" {
+			t.Errorf("handled %v: read %q, want %q", handled, b, "keep")
+		}
+		if handled && dm != 1 {
+			t.Errorf("DM handler called %d times, want 1", dm)
+		}
+	}
+}
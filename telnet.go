@@ -10,8 +10,13 @@
 package telnet
 
 import (
+	"bytes"
 	"io"
+	"log/slog"
+	"net"
+	"slices"
 	"sync"
+	"time"
 )
 
 //go:generate stringer -type Command
@@ -36,13 +41,23 @@ const (
 	EL                        // Erase Line
 	GA                        // Go Ahead
 	sb                        // Subnegotiation Begin
-	will                      // Desire to begin performing or confirmation nsNow performing
+	will                      // Desire to begin performing or confirmation now performing
 	wont                      // Refusal to perform or continue to perform
 	do                        // Request other party perform or confirm expecting
-	dont                      // Demand other party stop or confirm nsNo longer expecting
+	dont                      // Demand other party stop or confirm no longer expecting
 	iac                       // Interpret as Command
 )
 
+// DefaultMaxParams is the default maximum length of subnegotiation
+// parameters.
+const DefaultMaxParams = 4096
+
+// Option codes that affect the behavior of the context itself.
+const (
+	optBinary byte = 0 // Binary Transmission
+	optSGA    byte = 3 // Suppress Go Ahead
+)
+
 // Option is an interface for implementing telnet options.
 type Option interface {
 	// Byte returns the byte code of the option.
@@ -64,10 +79,23 @@ type Option interface {
 	SetUs(tn *Ctx, enabled bool)
 }
 
+// ParamsLimiter is an optional interface implemented by options that
+// limit the length of their subnegotiation parameters, overriding the
+// context maximum.
+type ParamsLimiter interface {
+	// MaxParams returns the maximum length of subnegotiation parameters.
+	// Zero or less means unlimited.
+	MaxParams() int
+}
+
 // optState is the state of an option.
 type optState struct {
 	opt     Option
-	him, us negState
+	him, us NegState
+
+	// remove indicates the option is removed once it's disabled for both
+	// him and us.
+	remove bool
 }
 
 // optStates holds the state of each option.
@@ -85,6 +113,11 @@ func (os optStates) load(code byte) optState {
 
 // store updates the state of an option.
 func (os optStates) store(s optState) {
+	if s.remove && s.him == NegNo && s.us == NegNo {
+		delete(os, s.opt.Byte())
+		return
+	}
+
 	os[s.opt.Byte()] = s
 }
 
@@ -96,35 +129,245 @@ type Ctx struct {
 	// over TCP this is typically a net.Conn.
 	rw io.ReadWriter
 
+	// reading indicates a Read is parsing, including while option and
+	// command callbacks are running.
+	reading bool
+	// upgrade replaces rw once the current command is parsed.
+	upgrade func(net.Conn) net.Conn
+
 	// rs is the Reader state.  It's either reading data or in various stages
 	// of parsing a command.
 	rs readState
 	// cb is the Reader command buffer.  It is filled until a complete command
 	// sequence is reached and then executed.
 	cb []byte
+	// overflow indicates the subnegotiation in the command buffer exceeded
+	// the maximum length and is being discarded.
+	overflow bool
+	// maxParams is the maximum length of subnegotiation parameters.
+	maxParams int
+	// discard indicates urgent mode, where data is discarded until a Data
+	// Mark is received.
+	discard bool
 
 	// os holds the state of each option.
 	os optStates
-
-	// pending holds data parsed during an empty-buffer Read that would
-	// otherwise be discarded.
+	// negTimeout is how long to wait for him to answer a negotiation.
+	negTimeout time.Duration
+	// negRetries is how many times a negotiation is re-sent.
+	negRetries int
+	// timeouth is called when a negotiation times out.
+	timeouth func(tn *Ctx, opt Option, side Side)
+	// timers holds the pending negotiation timers.
+	timers map[timerKey]*negTimer
+	// unknownh is consulted when an unknown option code is received.
+	unknownh func(tn *Ctx, code byte) Option
+	// unknown holds the unknown option codes he attempted to negotiate.
+	unknown []byte
+
+	// errh is called when a protocol error is reported.
+	errh func(tn *Ctx, err error)
+	// fatal indicates if protocol errors are returned by Read.
+	fatal bool
+	// ferr is a fatal protocol error waiting to be returned by Read.
+	ferr error
+
+	// obs receives negotiation events.
+	obs Observer
+
+	// cmds holds the registered command handlers.
+	cmds map[Command]CmdHandler
+	// ayt produces the Are You There response.
+	ayt func() []byte
+	// nvt indicates if NVT end of line translation is enabled.
+	nvt bool
+	// wcr indicates a carriage return was written without its following
+	// NUL or LF.
+	wcr bool
+
+	// pending holds parsed data that has not been returned by Read yet.
 	pending []byte
 }
 
 // NewReadWriter allocates a new ReadWriter that intercepts and handles
 // telnet negotiations and dispatches remaining data to rw.  Any options
 // that are provided will be available for negotiation.
+//
+// If rw is a net.Conn then the context can be used as one, with deadlines,
+// addresses, and Close delegated to rw.
 func NewReadWriter(rw io.ReadWriter, opts ...Option) *Ctx {
-	t := &Ctx{rw: rw}
+	t := &Ctx{
+		rw:        rw,
+		maxParams: DefaultMaxParams,
+		timers:    make(map[timerKey]*negTimer),
+		cmds:      make(map[Command]CmdHandler),
+		ayt:       defaultAYT,
+	}
 
 	t.os = make(optStates)
 	for _, opt := range opts {
 		t.os.store(optState{opt: opt})
 	}
 
+	if conn, ok := rw.(*net.TCPConn); ok {
+		if err := setOOBInline(conn); err != nil {
+			slog.Warn("unable to receive urgent data in-band", "err", err)
+		}
+	}
+
 	return t
 }
 
+// SetMaxParams sets the maximum length of subnegotiation parameters for
+// options that don't implement ParamsLimiter.  Longer subnegotiations are
+// discarded and a *ParamsLenError is reported.  Zero or less means
+// unlimited.
+func (t *Ctx) SetMaxParams(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxParams = n
+}
+
+// HandleErr registers a handler that is called when a protocol error,
+// such as a *NegotiationError, *ParamsError, or *ParamsLenError, is
+// reported.  A nil handler removes it.
+func (t *Ctx) HandleErr(h func(tn *Ctx, err error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errh = h
+}
+
+// SetFatal sets if protocol errors are fatal.  If true then Read returns
+// protocol errors, in addition to them being reported to the handler.
+func (t *Ctx) SetFatal(fatal bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.fatal = fatal
+}
+
+// report reports a protocol error.
+func (t *Ctx) report(err error) {
+	if err == nil {
+		return
+	}
+	slog.Debug("protocol error", "err", err)
+
+	if t.fatal && t.ferr == nil {
+		t.ferr = err
+	}
+
+	if h := t.errh; h != nil {
+		t.mu.Unlock()
+		h(t, err)
+		t.mu.Lock()
+	}
+}
+
+// AddOption makes an option available for negotiation.  If an option with
+// the same byte code is already available then it's replaced and its
+// negotiation state is retained.  The replacement is then enabled for the
+// sides the option was enabled for.
+func (t *Ctx) AddOption(opt Option) {
+	t.mu.Lock()
+	s := t.os[opt.Byte()]
+	s.opt, s.remove = opt, false
+	t.os.store(s)
+	t.mu.Unlock()
+
+	if active(s.him) {
+		opt.SetHim(t, true)
+	}
+	if active(s.us) {
+		opt.SetUs(t, true)
+	}
+}
+
+// RemoveOption makes an option unavailable for negotiation.  If negotiate
+// is true then the option is first disabled for him and us and it's
+// removed once both are confirmed.  Otherwise it's removed immediately
+// and disabled for the sides it was enabled for, without notifying him.
+func (t *Ctx) RemoveOption(opt Option, negotiate bool) {
+	t.mu.Lock()
+	s, found := t.os[opt.Byte()]
+	if !found {
+		t.mu.Unlock()
+		return
+	}
+
+	if !negotiate || (s.him == NegNo && s.us == NegNo) {
+		delete(t.os, opt.Byte())
+		t.changed(s, optState{opt: s.opt})
+		t.watch(opt.Byte())
+		t.mu.Unlock()
+
+		if active(s.him) {
+			s.opt.SetHim(t, false)
+		}
+		if active(s.us) {
+			s.opt.SetUs(t, false)
+		}
+		return
+	}
+
+	s.remove = true
+	t.os.store(s)
+	t.ask(wont, s.opt)
+	t.ask(dont, s.opt)
+	t.mu.Unlock()
+}
+
+// HandleUnknown registers a policy that is consulted when he negotiates
+// an option code that is not available.  It returns the Option to make
+// available for the code, such as an AcceptOpt, or nil to refuse it.  A nil
+// policy removes it.
+func (t *Ctx) HandleUnknown(f func(tn *Ctx, code byte) Option) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unknownh = f
+}
+
+// Unknown returns the unknown option codes he attempted to negotiate, in
+// the order they were first received.
+func (t *Ctx) Unknown() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.unknown)
+}
+
+// lookup retrieves the state of an option code received from him,
+// consulting the unknown option policy if it's not available.
+func (t *Ctx) lookup(code byte) optState {
+	if s, found := t.os[code]; found {
+		return s
+	}
+
+	if !slices.Contains(t.unknown, code) {
+		t.unknown = append(t.unknown, code)
+	}
+
+	if f := t.unknownh; f != nil {
+		t.mu.Unlock()
+		opt := f(t, code)
+		t.mu.Lock()
+
+		if s, found := t.os[code]; found {
+			return s
+		}
+		if opt != nil && opt.Byte() == code {
+			s := optState{opt: opt}
+			t.os.store(s)
+			return s
+		}
+	}
+
+	return t.os.load(code)
+}
+
 // AskHim asks him to enable or disable an option.
 func (t *Ctx) AskHim(opt Option, enable bool) error {
 	t.mu.Lock()
@@ -154,15 +397,18 @@ func (t *Ctx) SendCmd(cmd Command) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.emit(Event{Kind: EventCmdSent, Cmd: cmd})
 	t.rw.Write([]byte{byte(iac), byte(cmd)})
 }
 
-// SendParams sends option subnegotiation parameters.
+// SendParams sends option subnegotiation parameters.  Any Interpret as
+// Command bytes are escaped.
 func (t *Ctx) SendParams(opt Option, params []byte) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.emitParams(EventParamsSent, opt, params)
 	t.rw.Write([]byte{byte(iac), byte(sb), opt.Byte()})
-	t.rw.Write(params)
+	t.rw.Write(bytes.ReplaceAll(params, []byte{byte(iac)}, []byte{byte(iac), byte(iac)}))
 	t.rw.Write([]byte{byte(iac), byte(se)})
 }
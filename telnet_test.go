@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+// rwbuf is a ReadWriter that reads from a fixed input and records the
+// output.
+type rwbuf struct {
+	in  *bytes.Reader
+	out bytes.Buffer
+}
+
+func newRWBuf(in string) *rwbuf {
+	return &rwbuf{in: bytes.NewReader([]byte(in))}
+}
+
+func (rw *rwbuf) Read(b []byte) (int, error)  { return rw.in.Read(b) }
+func (rw *rwbuf) Write(b []byte) (int, error) { return rw.out.Write(b) }
+
+func TestRemoveOptionStopsTimers(t *testing.T) {
+	opt := AcceptOpt{Code: 24, Name: "Terminal-Type"}
+	tn := NewReadWriter(newRWBuf(""), opt)
+	tn.SetNegTimeout(10*time.Millisecond, 0)
+	expired := make(chan Side, 2)
+	tn.HandleNegTimeout(func(_ *Ctx, _ Option, side Side) { expired <- side })
+
+	tn.AskHim(opt, true)
+	tn.RemoveOption(opt, false)
+
+	select {
+	case side := <-expired:
+		t.Errorf("negotiation timed out for %s after the option was removed", side)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, found := tn.State(opt.Code).Opt.(AcceptOpt); found {
+		t.Error("option is still available after being removed")
+	}
+}
+
+func TestHandleUnknown(t *testing.T) {
+	neg := func(cmd Command, code byte) string { return string([]byte{byte(iac), byte(cmd), code}) }
+	in := neg(will, 24) + neg(do, 31) + neg(will, 24) + neg(will, 32)
+
+	tests := []struct {
+		name   string
+		policy bool
+		want   string
+		asked  []byte
+	}{
+		{"refused", false, neg(dont, 24) + neg(wont, 31) + neg(dont, 24) + neg(dont, 32), nil},
+		{"policy", true, neg(do, 24) + neg(wont, 31) + neg(dont, 32), []byte{24, 31, 32}},
+	}
+
+	for _, test := range tests {
+		rw := newRWBuf(in)
+		tn := NewReadWriter(rw)
+		var asked []byte
+		if test.policy {
+			tn.HandleUnknown(func(_ *Ctx, code byte) Option {
+				asked = append(asked, code)
+				if code == 24 {
+					return AcceptOpt{Code: code, Name: "Terminal-Type"}
+				}
+				return nil
+			})
+		}
+
+		if _, err := io.ReadAll(tn); err != nil {
+			t.Fatal(err)
+		}
+		if rw.out.String() != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, rw.out.String(), test.want)
+		}
+		if !slices.Equal(asked, test.asked) {
+			t.Errorf("%s: policy asked for %v, want %v", test.name, asked, test.asked)
+		}
+		if want := []byte{24, 31, 32}; !slices.Equal(tn.Unknown(), want) {
+			t.Errorf("%s: unknown %v, want %v", test.name, tn.Unknown(), want)
+		}
+	}
+}
+
+// setOpt is an option that records the sides it's enabled for.
+type setOpt struct {
+	AcceptOpt
+	him, us bool
+}
+
+func (o *setOpt) SetHim(tn *Ctx, enabled bool) { o.him = enabled }
+func (o *setOpt) SetUs(tn *Ctx, enabled bool)  { o.us = enabled }
+
+func TestReplaceRemoveOption(t *testing.T) {
+	opt := &setOpt{AcceptOpt: AcceptOpt{Code: 24, Name: "Terminal-Type"}}
+	tn := NewReadWriter(newRWBuf("\xff\xfb\x18\xff\xfd\x18"), opt)
+	if _, err := io.ReadAll(tn); err != nil {
+		t.Fatal(err)
+	}
+	if !opt.him || !opt.us {
+		t.Fatalf("option enabled him %v us %v, want both", opt.him, opt.us)
+	}
+
+	// A replacement takes over the enabled sides.
+	repl := &setOpt{AcceptOpt: opt.AcceptOpt}
+	tn.AddOption(repl)
+	if !repl.him || !repl.us {
+		t.Errorf("replacement enabled him %v us %v, want both", repl.him, repl.us)
+	}
+
+	// Removing it immediately disables it.
+	tn.RemoveOption(repl, false)
+	if repl.him || repl.us {
+		t.Errorf("removed option enabled him %v us %v, want neither", repl.him, repl.us)
+	}
+	if tn.Enabled(repl, Him) || tn.Enabled(repl, Us) {
+		t.Error("removed option is still enabled")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"log/slog"
+	"time"
+)
+
+// timerKey identifies a negotiation timer.
+type timerKey struct {
+	code byte
+	side Side
+}
+
+// negTimer is a pending negotiation timer.
+type negTimer struct {
+	timer *time.Timer
+	tries int
+}
+
+// SetNegTimeout sets how long to wait for him to answer an option
+// negotiation and how many times the request is re-sent before giving up.
+// When it gives up the option is disabled and the timeout handler is
+// called.  Zero or less disables timeouts, which is the default.
+func (t *Ctx) SetNegTimeout(d time.Duration, retries int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.negTimeout, t.negRetries = d, retries
+}
+
+// HandleNegTimeout registers a handler that is called when an option
+// negotiation for a side times out.  A nil handler removes it.
+func (t *Ctx) HandleNegTimeout(h func(tn *Ctx, opt Option, side Side)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.timeouth = h
+}
+
+// wanting indicates if a negotiation state is waiting for an answer.
+func wanting(ns NegState) bool {
+	return ns != NegNo && ns != NegYes
+}
+
+// watch starts or stops the negotiation timers for an option code based
+// on its state.
+func (t *Ctx) watch(code byte) {
+	s := t.os.load(code)
+	for _, side := range []Side{Him, Us} {
+		k := timerKey{code: code, side: side}
+		nt, found := t.timers[k]
+		want := wanting(OptionState{Him: s.him, Us: s.us}.Side(side))
+
+		switch {
+		case want && !found && t.negTimeout > 0:
+			nt = &negTimer{}
+			nt.timer = time.AfterFunc(t.negTimeout, func() { t.expire(k, nt) })
+			t.timers[k] = nt
+		case !want && found:
+			nt.timer.Stop()
+			delete(t.timers, k)
+		}
+	}
+}
+
+// expire is called when a negotiation timer expires.  The request is
+// re-sent if there are retries remaining, otherwise the option is
+// disabled.
+func (t *Ctx) expire(k timerKey, nt *negTimer) {
+	t.mu.Lock()
+	if t.timers[k] != nt {
+		// Stale timer
+		t.mu.Unlock()
+		return
+	}
+
+	s := t.os.load(k.code)
+	old := s
+	ns := &s.him
+	yes, no, setter := do, dont, s.opt.SetHim
+	if k.side == Us {
+		ns = &s.us
+		yes, no, setter = will, wont, s.opt.SetUs
+	}
+
+	if nt.tries < t.negRetries {
+		nt.tries++
+		slog.Debug("retrying option", "opt", s.opt, "side", k.side, "try", nt.tries)
+		switch *ns {
+		case NegWantYes, NegWantYesOpp:
+			t.indicate(yes, k.code)
+		case NegWantNo, NegWantNoOpp:
+			t.indicate(no, k.code)
+		}
+		nt.timer.Reset(t.negTimeout)
+		t.mu.Unlock()
+		return
+	}
+
+	slog.Debug("option timed out", "opt", s.opt, "side", k.side, "state", *ns)
+	delete(t.timers, k)
+
+	// The option was enabled until a disable was requested so it's now
+	// disabled without confirmation.
+	var callback func(*Ctx, bool)
+	if *ns == NegWantNo || *ns == NegWantNoOpp {
+		callback = setter
+	}
+	*ns = NegNo
+	t.changed(old, s)
+	t.emit(Event{Kind: EventTimeout, Opt: s.opt, Side: k.side})
+	t.os.store(s)
+	h := t.timeouth
+	t.mu.Unlock()
+
+	if callback != nil {
+		callback(t, false)
+	}
+	if h != nil {
+		h(t, s.opt, k.side)
+	}
+}
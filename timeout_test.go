@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuf is a ReadWriter that reads from a fixed input and records the
+// output, which may be written by timers.
+type syncBuf struct {
+	mu  sync.Mutex
+	in  *strings.Reader
+	out bytes.Buffer
+}
+
+func (b *syncBuf) Read(p []byte) (int, error) { return b.in.Read(p) }
+
+func (b *syncBuf) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.out.Write(p)
+}
+
+func (b *syncBuf) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.out.String()
+}
+
+func TestNegTimeout(t *testing.T) {
+	neg := func(cmd Command) string { return string([]byte{byte(iac), byte(cmd), 24}) }
+
+	tests := []struct {
+		name    string
+		in      string
+		enable  bool
+		retries int
+		want    string
+	}{
+		{"enable", "", true, 2, neg(do) + neg(do) + neg(do)},
+		{"disable", neg(will), false, 1, neg(do) + neg(dont) + neg(dont)},
+	}
+
+	for _, test := range tests {
+		opt := &setOpt{AcceptOpt: AcceptOpt{Code: 24, Name: "Terminal-Type"}}
+		rw := &syncBuf{in: strings.NewReader(test.in)}
+		tn := NewReadWriter(rw, opt)
+		if _, err := io.ReadAll(tn); err != nil {
+			t.Fatal(err)
+		}
+
+		var mu sync.Mutex
+		var timeouts int
+		tn.SetObserver(ObserverFunc(func(_ *Ctx, e Event) {
+			if e.Kind == EventTimeout {
+				mu.Lock()
+				timeouts++
+				mu.Unlock()
+			}
+		}))
+		expired := make(chan Side, 4)
+		tn.HandleNegTimeout(func(_ *Ctx, _ Option, side Side) { expired <- side })
+		tn.SetNegTimeout(10*time.Millisecond, test.retries)
+
+		tn.AskHim(opt, test.enable)
+		select {
+		case side := <-expired:
+			if side != Him {
+				t.Errorf("%s: timed out for %s, want %s", test.name, side, Him)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: negotiation did not time out", test.name)
+		}
+
+		// Make sure it only times out once.
+		time.Sleep(50 * time.Millisecond)
+		if n := len(expired); n > 0 {
+			t.Errorf("%s: timed out %d more times", test.name, n)
+		}
+		mu.Lock()
+		if timeouts != 1 {
+			t.Errorf("%s: %d timeout events, want 1", test.name, timeouts)
+		}
+		mu.Unlock()
+
+		if got := rw.String(); got != test.want {
+			t.Errorf("%s: wrote %q, want %q", test.name, got, test.want)
+		}
+		if s := tn.State(24).Him; s != NegNo {
+			t.Errorf("%s: state %s, want %s", test.name, s, NegNo)
+		}
+		if opt.him {
+			t.Errorf("%s: option is still enabled for him", test.name)
+		}
+	}
+}
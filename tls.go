@@ -0,0 +1,83 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+)
+
+// ConnectionState returns the TLS state of the underlying connection.  If
+// it's not a TLS connection then false is returned.
+func (t *Ctx) ConnectionState() (tls.ConnectionState, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.rw.(interface{ ConnectionState() tls.ConnectionState }); ok {
+		return c.ConnectionState(), true
+	}
+
+	return tls.ConnectionState{}, false
+}
+
+// DialTLS connects to the address on the named network using TLS and
+// returns a telnet context for the connection.  A nil configuration uses
+// the defaults.  Options are handled the same as Dial.
+func DialTLS(network, addr string, config *tls.Config, opts ...Option) (*Ctx, error) {
+	return DialTLSContext(context.Background(), network, addr, config, opts...)
+}
+
+// DialTLSContext is like DialTLS but connects using the provided context.
+func DialTLSContext(ctx context.Context, network, addr string, config *tls.Config, opts ...Option) (*Ctx, error) {
+	d := tls.Dialer{Config: config}
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReadWriter(conn, append(clientOpts, opts...)...), nil
+}
+
+// ListenAndServeTLS listens on the TCP network address Addr and then calls
+// ServeTLS.  If Addr is empty then ":992" is used.
+func (srv *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":992"
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return srv.ServeTLS(l, certFile, keyFile)
+}
+
+// ServeTLS accepts connections on the listener and performs a TLS
+// handshake before calling the handler for each session.
+//
+// The certificate and key files are loaded unless TLSConfig already has a
+// certificate configured, in which case they may be empty.  Client
+// certificates are requested according to TLSConfig and are available to
+// the handler through ConnectionState.
+func (srv *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
+	config := srv.TLSConfig.Clone()
+	if config == nil {
+		config = &tls.Config{}
+	}
+
+	hasCert := len(config.Certificates) > 0 || config.GetCertificate != nil
+	if !hasCert || certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	return srv.Serve(tls.NewListener(l, config))
+}
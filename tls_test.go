@@ -0,0 +1,142 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ebarkie/telnet/internal/testcert"
+)
+
+// writeCert writes a certificate and its key to PEM files and returns
+// their names.
+func writeCert(t *testing.T, cert tls.Certificate) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// startTLSServer starts serving TLS on a local listener and returns its
+// address.
+func startTLSServer(t *testing.T, srv *Server, certFile, keyFile string) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.ServeTLS(l, certFile, keyFile)
+	t.Cleanup(func() { srv.Close() })
+
+	return l.Addr().String()
+}
+
+func TestTLS(t *testing.T) {
+	serverCert, serverPool := testcert.New(t, "localhost")
+	certFile, keyFile := writeCert(t, serverCert)
+
+	srv := &Server{
+		Handler: HandlerFunc(func(s *Session) {
+			if _, ok := s.ConnectionState(); !ok {
+				t.Error("server session is not TLS")
+			}
+			s.Write([]byte("hello"))
+		}),
+	}
+	addr := startTLSServer(t, srv, certFile, keyFile)
+
+	tn, err := DialTLS("tcp", addr, &tls.Config{ServerName: "localhost", RootCAs: serverPool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tn.Close()
+
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("read %q, want %q", b, "hello")
+	}
+	if cs, ok := tn.ConnectionState(); !ok || !cs.HandshakeComplete {
+		t.Errorf("client connection state %v, handshake complete %v", ok, cs.HandshakeComplete)
+	}
+}
+
+func TestTLSClientCert(t *testing.T) {
+	serverCert, serverPool := testcert.New(t, "localhost")
+	clientCert, clientPool := testcert.New(t, "client")
+
+	srv := &Server{
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientPool,
+		},
+		Handler: HandlerFunc(func(s *Session) {
+			cs, _ := s.ConnectionState()
+			if len(cs.PeerCertificates) > 0 {
+				s.Write([]byte("hello " + cs.PeerCertificates[0].Subject.CommonName))
+			}
+		}),
+	}
+	addr := startTLSServer(t, srv, "", "")
+
+	tn, err := DialTLS("tcp", addr, &tls.Config{
+		ServerName:   "localhost",
+		RootCAs:      serverPool,
+		Certificates: []tls.Certificate{clientCert},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tn.Close()
+
+	b, err := io.ReadAll(tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello client" {
+		t.Errorf("read %q, want %q", b, "hello client")
+	}
+
+	// A client without a certificate is refused.
+	tn, err = DialTLS("tcp", addr, &tls.Config{ServerName: "localhost", RootCAs: serverPool})
+	if err == nil {
+		defer tn.Close()
+		_, err = io.ReadAll(tn)
+	}
+	if err == nil {
+		t.Error("client without a certificate was not refused")
+	}
+}
+
+func TestConnectionStateNotTLS(t *testing.T) {
+	tn := NewReadWriter(newRWBuf(""))
+	if _, ok := tn.ConnectionState(); ok {
+		t.Error("connection state reported for a ReadWriter that's not TLS")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Eric Barkie. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package telnet
+
+import (
+	"log/slog"
+	"net"
+	"slices"
+)
+
+// replayConn is a net.Conn that returns buffered bytes before reading
+// from the connection.
+type replayConn struct {
+	net.Conn
+	buf []byte
+}
+
+func (r *replayConn) Read(b []byte) (int, error) {
+	if len(r.buf) > 0 {
+		n := copy(b, r.buf)
+		r.buf = r.buf[n:]
+		return n, nil
+	}
+
+	return r.Conn.Read(b)
+}
+
+// Upgrade replaces the underlying connection with the one returned by
+// wrap, such as a *tls.Conn, while retaining the parser and option state.
+//
+// When called from an option or command callback during Read, the
+// replacement takes effect once the current command is parsed and any
+// bytes already read from the old connection are replayed to the new one.
+// Otherwise it takes effect immediately and no Read may be in progress.
+func (t *Ctx) Upgrade(wrap func(net.Conn) net.Conn) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rw.(net.Conn); !ok {
+		return ErrNotConn
+	}
+
+	t.upgrade = wrap
+	if !t.reading {
+		t.swap(nil)
+	}
+
+	return nil
+}
+
+// swap replaces the underlying connection using the pending upgrade.
+// Leftover bytes are replayed to the new connection.
+func (t *Ctx) swap(leftover []byte) {
+	slog.Debug("upgrading connection", "leftover", len(leftover))
+
+	conn := t.rw.(net.Conn)
+	if len(leftover) > 0 {
+		conn = &replayConn{Conn: conn, buf: slices.Clone(leftover)}
+	}
+	t.rw = t.upgrade(conn)
+	t.upgrade = nil
+}